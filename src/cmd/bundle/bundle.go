@@ -0,0 +1,19 @@
+/*
+Copyright © 2024 MavenCode <opensource-dev@mavencode.com>
+*/
+package bundle
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// BundleCmd represents the bundle command
+var BundleCmd = &cobra.Command{
+	Use:   "bundle",
+	Short: "manage installation bundles",
+	Long:  `manage bundles of the manifests and charts init installs, for clusters without internet access`,
+}
+
+func init() {
+	BundleCmd.AddCommand(CreateCmd)
+}
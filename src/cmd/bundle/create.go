@@ -0,0 +1,103 @@
+package bundle
+
+import (
+	"fmt"
+
+	"github.com/BeamStackProj/beamstack-cli/src/types"
+	"github.com/BeamStackProj/beamstack-cli/src/utils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	createBundleLongDesc = utils.LongDesc(`
+		Download every manifest and helm chart init installs for the selected
+		components into a tarball, along with their checksums. The bundle can
+		then be installed with 'beamstack init --bundle PATH' on a cluster without
+		internet access. Container images are not bundled and have to be
+		mirrored to a registry the cluster can reach.
+		`)
+
+	createBundleExample = utils.Examples(`
+		# Bundle flink and the monitoring stack
+		beamstack bundle create --monitoring -o beamstack-bundle.tar.gz
+
+		# Bundle the components and versions of a profile configuration file
+		beamstack bundle create -c profile.yaml
+		`)
+
+	output        string = "beamstack-bundle.tar.gz"
+	configFile    string = ""
+	flinkVersion  string = types.FlinkOperatorVersion
+	monitoring    bool   = false
+	elasticsearch bool   = false
+	kafka         bool   = false
+	objectStore   bool   = false
+)
+
+// CreateCmd represents the bundle create command
+var CreateCmd = &cobra.Command{
+	Use:     "create",
+	Short:   "create an installation bundle",
+	Long:    createBundleLongDesc,
+	Example: createBundleExample,
+	Run: func(cmd *cobra.Command, args []string) {
+		config, err := bundleConfig(cmd)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		artifacts := []types.Artifact{}
+		for _, component := range types.Components {
+			if config.Has(component.Name) {
+				artifacts = append(artifacts, types.ComponentArtifacts(component.Name, config)...)
+			}
+		}
+
+		if err := utils.CreateBundle(output, artifacts); err != nil {
+			fmt.Println(err)
+			return
+		}
+		fmt.Printf("bundle written to %s\n", output)
+	},
+}
+
+func init() {
+	CreateCmd.Flags().StringVarP(&output, "output", "o", output, "Path of the bundle to write.")
+	CreateCmd.Flags().StringVarP(&configFile, "config", "c", configFile, "Path to a profile configuration file. The components and their versions are read from it.")
+	CreateCmd.Flags().StringVarP(&flinkVersion, "flink-version", "f", flinkVersion, "Flink kubernetes operator version to bundle.")
+	CreateCmd.Flags().BoolVarP(&monitoring, "monitoring", "m", monitoring, "If specified, bundle the prometheus + grafana stack")
+	CreateCmd.Flags().BoolVarP(&elasticsearch, "elasticsearch", "e", elasticsearch, "If specified, bundle the elasticsearch operator")
+	CreateCmd.Flags().BoolVar(&kafka, "kafka", kafka, "If specified, bundle the strimzi kafka operator")
+	CreateCmd.Flags().BoolVar(&objectStore, "object-store", objectStore, "If specified, bundle minio")
+}
+
+// bundleConfig returns the configuration to bundle, loaded from the
+// configuration file or built from the flags.
+func bundleConfig(cmd *cobra.Command) (types.ProfileConfig, error) {
+	if configFile != "" {
+		for _, flag := range []string{"flink-version", "monitoring", "elasticsearch", "kafka", "object-store"} {
+			if cmd.Flags().Changed(flag) {
+				return types.ProfileConfig{}, fmt.Errorf("--%s cannot be used with --config. set it in the configuration file instead", flag)
+			}
+		}
+		return utils.LoadProfileConfig(configFile)
+	}
+
+	config := types.ProfileConfig{
+		Components: map[string]types.ComponentConfig{
+			types.ComponentFlink: {Version: flinkVersion},
+		},
+	}
+	for name, selected := range map[string]bool{
+		types.ComponentMonitoring:    monitoring,
+		types.ComponentElasticsearch: elasticsearch,
+		types.ComponentKafka:         kafka,
+		types.ComponentObjectStore:   objectStore,
+	} {
+		if selected {
+			config.Components[name] = types.ComponentConfig{}
+		}
+	}
+	return config, nil
+}
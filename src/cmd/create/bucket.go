@@ -0,0 +1,105 @@
+package create
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/BeamStackProj/beamstack-cli/src/objects"
+	"github.com/BeamStackProj/beamstack-cli/src/types"
+	"github.com/BeamStackProj/beamstack-cli/src/utils"
+	"github.com/spf13/cobra"
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+var (
+	bucketLongDesc = utils.LongDesc(`
+		Create a bucket on the object store installed with 'beamstack init --object-store'.
+		`)
+)
+
+// BucketCmd represents the create bucket command
+var BucketCmd = &cobra.Command{
+	Use:   "bucket [NAME]",
+	Short: "create object store bucket",
+	Long:  bucketLongDesc,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			return fmt.Errorf("bucket command requires exactly one argument: bucket Name. Provided %d arguments", len(args))
+		}
+		return nil
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		profile, err := utils.ValidateCluster()
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		if profile.ObjectStore == nil {
+			fmt.Println("Object store not initialized on this cluster")
+			return
+		}
+		store := profile.ObjectStore
+
+		clientset, err := kubernetes.NewForConfig(utils.GetKubeConfig())
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		BackOffLimit := int32(2)
+		job, err := objects.CreateJob(clientset, batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      fmt.Sprintf("create-bucket-%s", args[0]),
+				Namespace: store.Namespace,
+				Labels:    utils.EnvironmentLabels(nil),
+			},
+			Spec: batchv1.JobSpec{
+				BackoffLimit: &BackOffLimit,
+				Template: v1.PodTemplateSpec{
+					Spec: v1.PodSpec{
+						RestartPolicy: "Never",
+						Containers: []v1.Container{
+							{
+								Name:    "mc",
+								Image:   types.MinioClientImage,
+								Command: types.MinioClientCommand("mb", "--ignore-existing", fmt.Sprintf("%s/%s", types.ObjectStoreAlias, args[0])),
+								Env:     store.CredentialsEnv(),
+							},
+						},
+					},
+				},
+			},
+		})
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		finished, err := objects.WaitForJob(clientset, job.Name, store.Namespace, 5*time.Minute)
+		failed := err != nil || objects.JobFailed(finished)
+		if failed {
+			if err != nil {
+				fmt.Println(err)
+			}
+			fmt.Printf("could not create bucket %s\n", args[0])
+			if logs, err := objects.JobLogs(clientset, job.Name, store.Namespace); err != nil {
+				fmt.Println(err)
+			} else {
+				fmt.Print(logs)
+			}
+		}
+
+		fg := metav1.DeletePropagationBackground
+		clientset.BatchV1().Jobs(store.Namespace).Delete(cmd.Context(), job.Name, metav1.DeleteOptions{PropagationPolicy: &fg})
+		if failed {
+			return
+		}
+
+		fmt.Println("Bucket created")
+		fmt.Printf("s3 uri: s3://%s \n", args[0])
+		fmt.Printf("incluster endpoint: %s \n", store.Endpoint)
+	},
+}
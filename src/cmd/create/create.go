@@ -18,4 +18,6 @@ func init() {
 
 	CreateCmd.AddCommand(FlinkClusterCmd)
 	CreateCmd.AddCommand(ElasticSearchCmd)
+	CreateCmd.AddCommand(KafkaCmd)
+	CreateCmd.AddCommand(BucketCmd)
 }
@@ -7,24 +7,51 @@ import (
 	"github.com/BeamStackProj/beamstack-cli/src/types"
 	"github.com/BeamStackProj/beamstack-cli/src/utils"
 	"github.com/spf13/cobra"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 var (
 	esLongDesc = utils.LongDesc(`
 		Create a elastic search with specified requirments.
+
+		Without role flags a single node set holding every role is created.
+		Storage and resource flags apply to every node set. A manifest with
+		the version and node sets of the cluster can be given instead.
+		`)
+
+	esExample = utils.Examples(`
+		# Create a single node elasticsearch cluster
+		beamstack create elasticsearch my-es
+
+		# Create dedicated master and data node sets with 50Gi volumes
+		beamstack create elasticsearch my-es --master-nodes 3 --data-nodes 2 --storage-size 50Gi --heap 2g --memory 4Gi
+
+		# Create an elasticsearch cluster from a manifest
+		beamstack create elasticsearch my-es -f es.yaml
 		`)
 
 	ElasticSearchVersion string = "8.15.0"
 	Nodes                uint16 = 1
-	esNamespace          string = "default"
+	kibana               bool   = false
+	esManifest           string = ""
+	masterNodes          uint16 = 0
+	dataNodes            uint16 = 0
+	ingestNodes          uint16 = 0
+	esStorageSize        string = ""
+	esStorageClass       string = ""
+	esHeap               string = ""
+	esCPU                string = ""
+	esMemory             string = ""
 )
 
 // infoCmd represents the info command
 var ElasticSearchCmd = &cobra.Command{
-	Use:   "elasticsearch [NAME]",
-	Short: "create elasticsearch cluster",
-	Long:  esLongDesc,
+	Use:     "elasticsearch [NAME]",
+	Short:   "create elasticsearch cluster",
+	Long:    esLongDesc,
+	Example: esExample,
 	Args: func(cmd *cobra.Command, args []string) error {
 		if len(args) != 1 {
 			return fmt.Errorf("es command requires exactly one argument: cluster Name. Provided %d arguments", len(args))
@@ -32,21 +59,25 @@ var ElasticSearchCmd = &cobra.Command{
 		return nil
 	},
 	Run: func(cmd *cobra.Command, args []string) {
+		profile, err := utils.ValidateCluster()
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		if !hasPackage(profile, types.EckPackage("").Name) {
+			fmt.Println("Elasticsearch operator not initialized on this cluster. please run 'beamstack init --elasticsearch'")
+			return
+		}
 
-		spec := types.EsSpec{
-			Version: ElasticSearchVersion,
-			NodeSets: []types.EsNodeSet{
-				{
-					Name:  args[0],
-					Count: Nodes,
-					Config: map[string]interface{}{
-						"node.store.allow_mmap": false,
-					},
-				},
-			},
+		esNamespace := utils.WorkloadNamespace("default")
+
+		spec, err := elasticSearchSpec(args[0])
+		if err != nil {
+			fmt.Println(err)
+			return
 		}
 
-		err := objects.CreateDynamicResource(
+		err = objects.CreateDynamicResource(
 			metav1.TypeMeta{
 				APIVersion: "elasticsearch.k8s.elastic.co/v1",
 				Kind:       "Elasticsearch",
@@ -54,6 +85,7 @@ var ElasticSearchCmd = &cobra.Command{
 			metav1.ObjectMeta{
 				Name:      args[0],
 				Namespace: esNamespace,
+				Labels:    utils.EnvironmentLabels(nil),
 			},
 			spec,
 			"elasticsearches",
@@ -61,15 +93,198 @@ var ElasticSearchCmd = &cobra.Command{
 
 		if err != nil {
 			fmt.Println(err)
+			return
 		}
 		fmt.Println("Elastic search created")
-		fmt.Printf("incluster url: http://%s-es-http.default.svc.cluster.local:9200 \n", args[0])
-		fmt.Printf("Elasticsearch Password can be retrieved using kubectl \n kubectl get secret %s-es-elastic-user -n %s -o go-template='{{.data.elastic | base64decode}}'\n", args[0], esNamespace)
+		fmt.Printf("incluster url: %s \n", types.EsURL(args[0], esNamespace, spec.TLS()))
+
+		if kibana {
+			err := objects.CreateDynamicResource(
+				metav1.TypeMeta{
+					APIVersion: "kibana.k8s.elastic.co/v1",
+					Kind:       "Kibana",
+				},
+				metav1.ObjectMeta{
+					Name:      args[0],
+					Namespace: esNamespace,
+					Labels:    utils.EnvironmentLabels(nil),
+				},
+				types.KibanaSpec{
+					Version: ElasticSearchVersion,
+					Count:   1,
+					ElasticsearchRef: types.KibanaElasticsearchRef{
+						Name: args[0],
+					},
+				},
+				"kibanas",
+			)
+			if err != nil {
+				fmt.Println(err)
+			} else {
+				fmt.Println("Kibana created")
+				fmt.Printf("Kibana can be opened using \n beamstack open kibana %s --namespace %s\n", args[0], esNamespace)
+			}
+		}
+
+		fmt.Printf("Elasticsearch credentials can be retrieved using \n beamstack get credentials elasticsearch %s --namespace %s\n", args[0], esNamespace)
 	},
 }
 
 func init() {
 	ElasticSearchCmd.Flags().StringVar(&ElasticSearchVersion, "version", ElasticSearchVersion, "elastic search version")
-	ElasticSearchCmd.Flags().Uint16Var(&Nodes, "nodes", Nodes, "number of elastic search Nodes")
-	ElasticSearchCmd.Flags().StringVar(&esNamespace, "namespace", esNamespace, "namespace to deploy create elasticsearch cluster on")
+	ElasticSearchCmd.Flags().Uint16Var(&Nodes, "nodes", Nodes, "number of elastic search Nodes. Ignored if any role flag is specified")
+	ElasticSearchCmd.Flags().Uint16Var(&masterNodes, "master-nodes", masterNodes, "number of dedicated master nodes")
+	ElasticSearchCmd.Flags().Uint16Var(&dataNodes, "data-nodes", dataNodes, "number of dedicated data nodes")
+	ElasticSearchCmd.Flags().Uint16Var(&ingestNodes, "ingest-nodes", ingestNodes, "number of dedicated ingest nodes")
+	ElasticSearchCmd.Flags().StringVar(&esStorageSize, "storage-size", esStorageSize, "size of the data volume of each node. defaults to 1Gi")
+	ElasticSearchCmd.Flags().StringVar(&esStorageClass, "storage-class", esStorageClass, "storage class of the data volumes. The cluster default class is used if not provided")
+	ElasticSearchCmd.Flags().StringVar(&esHeap, "heap", esHeap, "JVM heap size of each node, e.g. 2g")
+	ElasticSearchCmd.Flags().StringVar(&esCPU, "cpu", esCPU, "cpu requested and limited for each node")
+	ElasticSearchCmd.Flags().StringVar(&esMemory, "memory", esMemory, "memory requested and limited for each node")
+	ElasticSearchCmd.Flags().StringVarP(&esManifest, "file", "f", esManifest, "path to a manifest with the version and node sets of the cluster. Node, storage and resource flags are ignored")
+	ElasticSearchCmd.Flags().BoolVar(&kibana, "kibana", kibana, "If specified, also create a kibana instance connected to the elasticsearch cluster")
+}
+
+// hasPackage reports whether init installed, or adopted, a package on the cluster.
+func hasPackage(profile types.Profiles, name string) bool {
+	_, ok := packageNamespace(profile, name)
+	return ok
+}
+
+// packageNamespace returns the namespace of a package init installed, or
+// adopted, on the cluster.
+func packageNamespace(profile types.Profiles, name string) (string, bool) {
+	for _, pkg := range profile.Packages {
+		if pkg.Name == name {
+			return pkg.Namespace, true
+		}
+	}
+	return "", false
+}
+
+// elasticSearchSpec builds the spec of the cluster from the manifest or from
+// the node set, storage and resource flags.
+func elasticSearchSpec(name string) (types.EsSpec, error) {
+	if esManifest != "" {
+		spec := types.EsSpec{}
+		if err := utils.ParseYAMLAsJSON(esManifest, &spec); err != nil {
+			return spec, err
+		}
+		if spec.Version == "" {
+			spec.Version = ElasticSearchVersion
+		}
+		if len(spec.NodeSets) == 0 {
+			return spec, fmt.Errorf("manifest %s defines no node sets", esManifest)
+		}
+		return spec, nil
+	}
+
+	nodeSets := []types.EsNodeSet{}
+	for _, role := range []struct {
+		name  string
+		count uint16
+	}{
+		{"master", masterNodes},
+		{"data", dataNodes},
+		{"ingest", ingestNodes},
+	} {
+		if role.count > 0 {
+			nodeSets = append(nodeSets, types.EsNodeSet{
+				Name:  role.name,
+				Count: role.count,
+				Config: map[string]interface{}{
+					"node.roles": []interface{}{role.name},
+				},
+			})
+		}
+	}
+	if len(nodeSets) == 0 {
+		nodeSets = append(nodeSets, types.EsNodeSet{
+			Name:   name,
+			Count:  Nodes,
+			Config: map[string]interface{}{},
+		})
+	} else if masterNodes == 0 {
+		return types.EsSpec{}, fmt.Errorf("dedicated node sets require at least one master node")
+	} else if dataNodes == 0 {
+		return types.EsSpec{}, fmt.Errorf("dedicated node sets require at least one data node to hold shards")
+	}
+
+	for i := range nodeSets {
+		nodeSets[i].Config["node.store.allow_mmap"] = false
+		if err := configureEsNodeSet(&nodeSets[i]); err != nil {
+			return types.EsSpec{}, err
+		}
+	}
+
+	return types.EsSpec{
+		Version:  ElasticSearchVersion,
+		NodeSets: nodeSets,
+	}, nil
+}
+
+// configureEsNodeSet sets the data volume and the resources of the
+// elasticsearch container of a node set from the flags.
+func configureEsNodeSet(nodeSet *types.EsNodeSet) error {
+	if esStorageSize != "" || esStorageClass != "" {
+		size := esStorageSize
+		if size == "" {
+			size = "1Gi"
+		}
+		quantity, err := resource.ParseQuantity(size)
+		if err != nil {
+			return fmt.Errorf("invalid storage size %s: %s", size, err)
+		}
+
+		claim := v1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: types.EsDataVolumeName},
+			Spec: v1.PersistentVolumeClaimSpec{
+				AccessModes: []v1.PersistentVolumeAccessMode{v1.ReadWriteOnce},
+				Resources: v1.VolumeResourceRequirements{
+					Requests: v1.ResourceList{v1.ResourceStorage: quantity},
+				},
+			},
+		}
+		if esStorageClass != "" {
+			claim.Spec.StorageClassName = &esStorageClass
+		}
+		nodeSet.VolumeClaimTemplates = []v1.PersistentVolumeClaim{claim}
+	}
+
+	if esHeap == "" && esCPU == "" && esMemory == "" {
+		return nil
+	}
+
+	container := v1.Container{Name: types.EsContainerName}
+	if esHeap != "" {
+		container.Env = append(container.Env, v1.EnvVar{
+			Name:  "ES_JAVA_OPTS",
+			Value: fmt.Sprintf("-Xms%s -Xmx%s", esHeap, esHeap),
+		})
+	}
+
+	resources := v1.ResourceList{}
+	for name, value := range map[v1.ResourceName]string{v1.ResourceCPU: esCPU, v1.ResourceMemory: esMemory} {
+		if value == "" {
+			continue
+		}
+		quantity, err := resource.ParseQuantity(value)
+		if err != nil {
+			return fmt.Errorf("invalid %s %s: %s", name, value, err)
+		}
+		resources[name] = quantity
+	}
+	if len(resources) > 0 {
+		container.Resources = v1.ResourceRequirements{
+			Requests: resources,
+			Limits:   resources,
+		}
+	}
+
+	nodeSet.PodTemplate = &v1.PodTemplateSpec{
+		Spec: v1.PodSpec{
+			Containers: []v1.Container{container},
+		},
+	}
+	return nil
 }
@@ -1,14 +1,20 @@
 package create
 
 import (
+	"context"
 	"fmt"
+	"slices"
+	"strings"
+	"time"
 
+	info_handler "github.com/BeamStackProj/beamstack-cli/src/handlers/info"
 	"github.com/BeamStackProj/beamstack-cli/src/objects"
 	"github.com/BeamStackProj/beamstack-cli/src/types"
 	"github.com/BeamStackProj/beamstack-cli/src/utils"
 	"github.com/spf13/cobra"
 
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 )
@@ -22,8 +28,31 @@ var (
 	taskslots   uint8  = 1
 	replicas    uint8  = 1
 	Previledged bool   = false
+	harness     string = types.HarnessExternal
+	image       string = ""
+	ha          bool   = false
+	jmReplicas  uint8  = 2
+
+	storageClass  string        = ""
+	accessMode    string        = string(v1.ReadWriteMany)
+	existingClaim string        = ""
+	pvcTimeout    time.Duration = 2 * time.Minute
+
+	stateBackend        string = "hashmap"
+	checkpointDir       string = "file://" + clusterPVCMountPath + "/flink/checkpoints"
+	savepointDir        string = "file://" + clusterPVCMountPath + "/flink/savepoints"
+	checkpointsRetained uint8  = 3
+	checkpointRetention string = "RETAIN_ON_CANCELLATION"
+	s3Endpoint          string = ""
+	s3CredentialsSecret string = ""
+	s3Plugin            string = "flink-s3-fs-presto-1.16.3.jar"
+
+	schedulingFlags utils.SchedulingFlags
+	ignoreCapacity  bool = false
 )
 
+const clusterPVCMountPath = "/pvc"
+
 // Description and Examples for creating flink clsuters
 var (
 	flinkLongDesc = utils.LongDesc(`
@@ -53,7 +82,36 @@ var FlinkClusterCmd = &cobra.Command{
 			fmt.Println("Flink Operator not initialized on this cluster")
 			return
 		}
-		namespace := "flink"
+		namespace := utils.WorkloadNamespace(profile.Namespace(types.ComponentFlink))
+
+		if Previledged {
+			fmt.Println("--previledged is deprecated, use --environment docker")
+			harness = types.HarnessDocker
+		}
+		if !slices.Contains(types.Harnesses, harness) {
+			fmt.Printf("unsupported harness environment %s. use one of %s\n", harness, strings.Join(types.Harnesses, ", "))
+			return
+		}
+
+		if stateBackend != "hashmap" && stateBackend != "rocksdb" {
+			fmt.Printf("unsupported state backend %s. use hashmap or rocksdb\n", stateBackend)
+			return
+		}
+		if checkpointRetention != "RETAIN_ON_CANCELLATION" && checkpointRetention != "DELETE_ON_CANCELLATION" {
+			fmt.Printf("unsupported checkpoint retention %s. use RETAIN_ON_CANCELLATION or DELETE_ON_CANCELLATION\n", checkpointRetention)
+			return
+		}
+
+		scheduling, err := schedulingFlags.Resolve(profile.Scheduling)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		if err := checkClusterCapacity(args[0], scheduling); err != nil {
+			fmt.Println(err)
+			return
+		}
 
 		flinkVersion := "1.16"
 		flinkVersionLong := "v1_16"
@@ -68,203 +126,129 @@ var FlinkClusterCmd = &cobra.Command{
 			return
 		}
 
-		if err := objects.CreatePVC(clientset, ClaimName, namespace, volumeSize); err != nil {
-			fmt.Println(err)
+		// the operator chart only creates the flink service account in the namespaces it watches
+		if _, err := clientset.CoreV1().ServiceAccounts(namespace).Get(context.TODO(), "flink", metav1.GetOptions{}); err != nil {
+			fmt.Printf("could not find service account flink in namespace %s: %s\n", namespace, err)
+			fmt.Println("add the namespace to watchNamespaces of the flink operator with 'beamstack init --values flink-operator=FILE'")
 			return
 		}
 
-		var spec types.FlinkDeploymentSpec
-		workerImage := "beamstackproj/beam-harness:latest"
+		if existingClaim != "" {
+			ClaimName = existingClaim
+			claim, err := clientset.CoreV1().PersistentVolumeClaims(namespace).Get(context.TODO(), ClaimName, metav1.GetOptions{})
+			if err != nil {
+				fmt.Printf("could not find persistent volume claim %s: %s\n", ClaimName, err)
+				return
+			}
+			if !slices.Contains(claim.Spec.AccessModes, v1.ReadWriteMany) && !slices.Contains(claim.Spec.AccessModes, v1.ReadWriteOnce) {
+				fmt.Printf("persistent volume claim %s has access modes %v. the flink pods write to it, it needs ReadWriteMany or ReadWriteOnce\n", ClaimName, claim.Spec.AccessModes)
+				return
+			}
+			if !slices.Contains(claim.Spec.AccessModes, v1.ReadWriteMany) {
+				warnSingleNodeClaim(ClaimName)
+			}
+		} else {
+			mode := v1.PersistentVolumeAccessMode(accessMode)
+			switch mode {
+			case v1.ReadWriteMany:
+			case v1.ReadWriteOnce:
+				warnSingleNodeClaim(ClaimName)
+			case v1.ReadOnlyMany, v1.ReadWriteOncePod:
+				fmt.Printf("access mode %s is not supported. the task managers, job managers and migration pods all write to the claim, use ReadWriteMany or ReadWriteOnce\n", accessMode)
+				return
+			default:
+				fmt.Printf("unsupported access mode %s\n", accessMode)
+				return
+			}
+			if err := objects.CreatePVC(clientset, ClaimName, namespace, volumeSize, storageClass, mode); err != nil {
+				fmt.Println(err)
+				return
+			}
+		}
 
-		if Previledged {
-			// flinkImage := fmt.Sprintf("beamstackproj/flink-%s-docker:latest", flinkVersion)
-			flinkImage := fmt.Sprintf("flink:%s", flinkVersion)
-
-			spec = types.FlinkDeploymentSpec{
-				Image:           &flinkImage,
-				ImagePullPolicy: "IfNotPresent",
-				FlinkVersion:    flinkVersionLong,
-				FlinkConfiguration: map[string]string{
-					"taskmanager.numberOfTaskSlots": fmt.Sprintf("%d", taskslots),
-				},
-				ServiceAccount: "flink",
-				PodTemplate: &v1.PodTemplateSpec{
-					Spec: v1.PodSpec{
-						Containers: []v1.Container{
-							{
-								Name:         "flink-main-container",
-								Image:        flinkImage,
-								VolumeMounts: []v1.VolumeMount{},
-								SecurityContext: &v1.SecurityContext{
-									Privileged: func(b bool) *bool { return &b }(true),
-								},
-							},
-						},
-						Volumes: []v1.Volume{},
-					},
-				},
-				JobManager: types.JobManagerSpec{
-					Replicas: 1,
-					Resource: types.Resource{
-						Memory:      memory,
-						CPU:         cpu,
-						CPULimit:    cpuLimit,
-						MemoryLimit: memoryLimit,
-					},
-				},
-				TaskManager: types.TaskManagerSpec{
-					Replicas: replicas,
-					Resource: types.Resource{
-						Memory:      memory,
-						CPU:         cpu,
-						CPULimit:    cpuLimit,
-						MemoryLimit: memoryLimit,
-					},
+		fmt.Printf("waiting for persistent volume claim %s to bind\n", ClaimName)
+		if err := objects.WaitForPVCBound(clientset, ClaimName, namespace, pvcTimeout); err != nil {
+			fmt.Println(err)
+			return
+		}
 
-					PodTemplate: &v1.PodTemplateSpec{
-						Spec: v1.PodSpec{
-							Containers: []v1.Container{
-								{
-									Name:  "worker",
-									Image: workerImage,
-									Args:  []string{"-worker_pool"},
-									Ports: []v1.ContainerPort{
-										{
-											Name:          "harness-port",
-											ContainerPort: 50000,
-										},
-									},
-									VolumeMounts: []v1.VolumeMount{
-										{
-											MountPath: "/pvc",
-											Name:      "flink-cluster-pvc",
-										},
-									},
-								},
-								{
-									Name: "flink-main-container",
-									SecurityContext: &v1.SecurityContext{
-										Privileged: func(b bool) *bool { return &b }(true),
-									},
-									VolumeMounts: []v1.VolumeMount{
-										{
-											MountPath: "/var/run/docker.sock",
-											Name:      "docker-socket",
-										},
-									},
-								},
-							},
-							Volumes: []v1.Volume{
-								{
-									Name: "flink-cluster-pvc",
-									VolumeSource: v1.VolumeSource{
-										PersistentVolumeClaim: &v1.PersistentVolumeClaimVolumeSource{
-											ClaimName: ClaimName,
-										},
-									},
-								},
+		flinkImage := fmt.Sprintf("beamstackproj/flink-%s:latest", flinkVersionLong)
+		if harness == types.HarnessDocker {
+			flinkImage = fmt.Sprintf("flink:%s", flinkVersion)
+		}
+		if image != "" {
+			flinkImage = image
+		}
+
+		spec := types.FlinkDeploymentSpec{
+			Image:           &flinkImage,
+			ImagePullPolicy: "IfNotPresent",
+			FlinkVersion:    flinkVersionLong,
+			FlinkConfiguration: map[string]string{
+				"taskmanager.numberOfTaskSlots": fmt.Sprintf("%d", taskslots),
+			},
+			ServiceAccount: "flink",
+			PodTemplate: &v1.PodTemplateSpec{
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{
+						{
+							Name: "flink-main-container",
+							VolumeMounts: []v1.VolumeMount{
 								{
-									Name: "docker-socket",
-									VolumeSource: v1.VolumeSource{
-										HostPath: &v1.HostPathVolumeSource{
-											Path: "/var/run/docker.sock",
-											Type: func() *v1.HostPathType {
-												t := v1.HostPathSocket
-												return &t
-											}(),
-										},
-									},
+									MountPath: "/opt/flink/log",
+									Name:      "flink-logs",
 								},
 							},
 						},
 					},
-				},
-			}
-		} else {
-			flinkImage := fmt.Sprintf("beamstackproj/flink-%s:latest", flinkVersionLong)
-			// flinkImage := fmt.Sprintf("flink:%s", flinkVersion)
-			spec = types.FlinkDeploymentSpec{
-				Image:           &flinkImage,
-				ImagePullPolicy: "IfNotPresent",
-				FlinkVersion:    flinkVersionLong,
-				FlinkConfiguration: map[string]string{
-					"taskmanager.numberOfTaskSlots": fmt.Sprintf("%d", taskslots),
-				},
-				ServiceAccount: "flink",
-				PodTemplate: &v1.PodTemplateSpec{
-					Spec: v1.PodSpec{
-						Containers: []v1.Container{
-							{
-								Name: "flink-main-container",
-								VolumeMounts: []v1.VolumeMount{
-									{
-										MountPath: "/opt/flink/log",
-										Name:      "flink-logs",
-									},
-								},
-							},
-						},
-						Volumes: []v1.Volume{
-							{
-								Name: "flink-logs",
-							},
+					Volumes: []v1.Volume{
+						{
+							Name: "flink-logs",
 						},
 					},
 				},
-				JobManager: types.JobManagerSpec{
-					Replicas: 1,
-					Resource: types.Resource{
-						Memory:      memory,
-						CPU:         cpu,
-						CPULimit:    cpuLimit,
-						MemoryLimit: memoryLimit,
-					},
+			},
+			JobManager: types.JobManagerSpec{
+				Replicas: 1,
+				Resource: types.Resource{
+					Memory:      memory,
+					CPU:         cpu,
+					CPULimit:    cpuLimit,
+					MemoryLimit: memoryLimit,
 				},
-				TaskManager: types.TaskManagerSpec{
-					Replicas: replicas,
-					Resource: types.Resource{
-						Memory:      memory,
-						CPU:         cpu,
-						CPULimit:    cpuLimit,
-						MemoryLimit: memoryLimit,
-					},
-
-					PodTemplate: &v1.PodTemplateSpec{
-						Spec: v1.PodSpec{
-							Containers: []v1.Container{
-								{
-									Name:  "worker",
-									Image: workerImage,
-									Args:  []string{"-worker_pool"},
-									Ports: []v1.ContainerPort{
-										{
-											Name:          "harness-port",
-											ContainerPort: 50000,
-										},
-									},
-									VolumeMounts: []v1.VolumeMount{
-										{
-											MountPath: "/pvc",
-											Name:      "flink-cluster-pvc",
-										},
-									},
-								},
-							},
-							Volumes: []v1.Volume{
-								{
-									Name: "flink-cluster-pvc",
-									VolumeSource: v1.VolumeSource{
-										PersistentVolumeClaim: &v1.PersistentVolumeClaimVolumeSource{
-											ClaimName: ClaimName,
-										},
-									},
-								},
-							},
-						},
-					},
+			},
+			TaskManager: types.TaskManagerSpec{
+				Replicas: replicas,
+				Resource: types.Resource{
+					Memory:      memory,
+					CPU:         cpu,
+					CPULimit:    cpuLimit,
+					MemoryLimit: memoryLimit,
 				},
-			}
+				PodTemplate: &v1.PodTemplateSpec{},
+			},
+		}
+
+		switch harness {
+		case types.HarnessExternal:
+			configureExternalHarness(&spec, ClaimName)
+		case types.HarnessDocker:
+			configureDockerHarness(&spec, ClaimName)
+		case types.HarnessProcess:
+			configureProcessHarness(&spec, ClaimName)
+		case types.HarnessLoopback:
+			// the harness runs next to the pipeline job, task managers only need the flink runtime
+		}
+
+		configureStateBackend(&spec, ClaimName)
 
+		if spec.PodTemplate == nil {
+			spec.PodTemplate = &v1.PodTemplateSpec{}
+		}
+		scheduling.Apply(&spec.PodTemplate.Spec)
+
+		if ha {
+			configureHA(&spec, ClaimName)
 		}
 
 		err = objects.CreateDynamicResource(
@@ -274,7 +258,12 @@ var FlinkClusterCmd = &cobra.Command{
 			},
 			metav1.ObjectMeta{
 				Name:      args[0],
-				Namespace: "flink",
+				Namespace: namespace,
+				Labels:    utils.EnvironmentLabels(nil),
+				Annotations: map[string]string{
+					types.ClaimNameAnnotation: ClaimName,
+					types.HarnessAnnotation:   harness,
+				},
 			},
 			spec,
 			"flinkdeployments",
@@ -297,7 +286,312 @@ func init() {
 	FlinkClusterCmd.Flags().Uint8Var(&replicas, "replicas", replicas, "numbers of replicas sets for task manager")
 	FlinkClusterCmd.Flags().Uint8Var(&taskslots, "taskslots", taskslots, "numbers of taskslots to be created for the task manager")
 	FlinkClusterCmd.Flags().StringVar(&volumeSize, "volumeSize", volumeSize, "size of persistent volume to be attached to flink cluster")
+	FlinkClusterCmd.Flags().StringVar(&storageClass, "storage-class", storageClass, "storage class of the persistent volume claim. The cluster default storage class is used if not provided")
+	FlinkClusterCmd.Flags().StringVar(&accessMode, "access-mode", accessMode, "access mode of the persistent volume claim. One of ReadWriteMany or ReadWriteOnce. ReadWriteOnce only works while every flink pod runs on the same node")
+	FlinkClusterCmd.Flags().StringVar(&existingClaim, "existing-claim", existingClaim, "bind an existing persistent volume claim instead of creating one")
+	FlinkClusterCmd.Flags().DurationVar(&pvcTimeout, "pvc-timeout", pvcTimeout, "how long to wait for the persistent volume claim to bind")
 	FlinkClusterCmd.Flags().BoolVarP(&Previledged, "previledged", "p", Previledged, "")
+	FlinkClusterCmd.Flags().MarkDeprecated("previledged", "use --environment docker instead")
+	FlinkClusterCmd.Flags().StringVar(&harness, "environment", harness, "SDK harness environment of the cluster. One of external (worker pool sidecar), docker (privileged docker socket), process (harness process inside the task manager, the image must provide python with apache_beam) or loopback (harness next to the pipeline job, requires native sidecar support)")
+	FlinkClusterCmd.Flags().StringVar(&image, "image", image, "flink image of the cluster. Defaults to the beamstack flink image of the environment")
+	FlinkClusterCmd.Flags().BoolVar(&ha, "ha", ha, "Enable Kubernetes high availability for the job manager. HA metadata is stored on the cluster PVC")
+	FlinkClusterCmd.Flags().Uint8Var(&jmReplicas, "jobmanager-replicas", jmReplicas, "numbers of job manager replicas. Ignored if --ha is not specified")
+	FlinkClusterCmd.Flags().StringVar(&stateBackend, "state-backend", stateBackend, "state backend of the cluster. One of hashmap or rocksdb")
+	FlinkClusterCmd.Flags().StringVar(&checkpointDir, "checkpoint-dir", checkpointDir, "directory checkpoints are written to. Either a path on the cluster PVC or an s3:// uri")
+	FlinkClusterCmd.Flags().StringVar(&savepointDir, "savepoint-dir", savepointDir, "directory savepoints are written to. Either a path on the cluster PVC or an s3:// uri")
+	FlinkClusterCmd.Flags().Uint8Var(&checkpointsRetained, "checkpoints-retained", checkpointsRetained, "number of completed checkpoints to retain")
+	FlinkClusterCmd.Flags().StringVar(&checkpointRetention, "checkpoint-retention", checkpointRetention, "whether checkpoints are kept when a job is cancelled. One of RETAIN_ON_CANCELLATION or DELETE_ON_CANCELLATION")
+	FlinkClusterCmd.Flags().StringVar(&s3Endpoint, "s3-endpoint", s3Endpoint, "endpoint of an S3-compatible object store used for s3:// checkpoint and savepoint directories")
+	FlinkClusterCmd.Flags().StringVar(&s3CredentialsSecret, "s3-credentials-secret", s3CredentialsSecret, "secret with AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY keys used to access s3:// directories")
+	FlinkClusterCmd.Flags().StringVar(&s3Plugin, "s3-plugin", s3Plugin, "flink s3 filesystem plugin jar enabled for s3:// directories")
+	utils.AddSchedulingFlags(FlinkClusterCmd.Flags(), &schedulingFlags)
+	FlinkClusterCmd.Flags().BoolVar(&ignoreCapacity, "ignore-capacity", ignoreCapacity, "create the cluster even if the kubernetes cluster lacks the resources to schedule it")
+}
+
+// checkClusterCapacity refuses to create a cluster whose job and task
+// managers cannot be scheduled on the free resources of the nodes.
+func checkClusterCapacity(name string, scheduling types.Scheduling) error {
+	cpuQuantity, err := resource.ParseQuantity(cpu)
+	if err != nil {
+		return fmt.Errorf("invalid cpu %s: %s", cpu, err)
+	}
+	memoryQuantity, err := resource.ParseQuantity(memory)
+	if err != nil {
+		return fmt.Errorf("invalid memory %s: %s", memory, err)
+	}
+
+	jobManagers := uint8(1)
+	if ha {
+		jobManagers = jmReplicas
+	}
+
+	pods := []v1.ResourceList{}
+	for i := 0; i < int(jobManagers)+int(replicas); i++ {
+		pods = append(pods, v1.ResourceList{
+			v1.ResourceCPU:    cpuQuantity,
+			v1.ResourceMemory: memoryQuantity,
+		})
+	}
+
+	report, err := info_handler.CheckCapacity(pods, scheduling)
+	if err != nil {
+		fmt.Printf("could not check cluster capacity: %s\n", err)
+		return nil
+	}
+	if report.Fits() {
+		return nil
+	}
+
+	fmt.Printf("flink cluster %s does not fit on the kubernetes cluster:\n", name)
+	info_handler.PrintCapacityReport(report)
+	if ignoreCapacity {
+		fmt.Println("creating it anyway, pods may stay pending")
+		return nil
+	}
+	return fmt.Errorf("aborting. use --ignore-capacity to create the cluster anyway")
+}
+
+// warnSingleNodeClaim warns that a ReadWriteOnce claim can only be mounted
+// by pods of a single node, which breaks clusters running several task
+// managers or job managers on different nodes.
+func warnSingleNodeClaim(claimName string) {
+	if replicas > 1 || ha {
+		fmt.Printf("warning: persistent volume claim %s is ReadWriteOnce. pods of the cluster scheduled on other nodes cannot mount it, use ReadWriteMany with several task managers or --ha\n", claimName)
+	}
+}
+
+// configureHA switches the cluster to Kubernetes HA services and runs
+// several job managers. HA metadata is written to the cluster PVC, which is
+// mounted into the flink pods so that a restarted leader can recover
+// in-flight jobs.
+func configureHA(spec *types.FlinkDeploymentSpec, claimName string) {
+	spec.FlinkConfiguration["high-availability"] = "kubernetes"
+	spec.FlinkConfiguration["high-availability.storageDir"] = "file://" + clusterPVCMountPath + "/flink/ha"
+
+	spec.JobManager.Replicas = jmReplicas
+	mountClusterPVC(spec, claimName)
+}
+
+// configureStateBackend sets the state backend and the durable locations of
+// checkpoints and savepoints. Directories on the cluster PVC need the claim
+// mounted into the job and task managers, S3 directories need the presto s3 plugin and
+// optionally credentials from a secret.
+func configureStateBackend(spec *types.FlinkDeploymentSpec, claimName string) {
+	spec.FlinkConfiguration["state.backend"] = stateBackend
+	if stateBackend == "rocksdb" {
+		spec.FlinkConfiguration["state.backend.incremental"] = "true"
+	}
+	spec.FlinkConfiguration["state.checkpoints.dir"] = checkpointDir
+	spec.FlinkConfiguration["state.savepoints.dir"] = savepointDir
+	spec.FlinkConfiguration["state.checkpoints.num-retained"] = fmt.Sprintf("%d", checkpointsRetained)
+	spec.FlinkConfiguration["execution.checkpointing.externalized-checkpoint-retention"] = checkpointRetention
+
+	if strings.HasPrefix(checkpointDir, "file://"+clusterPVCMountPath) || strings.HasPrefix(savepointDir, "file://"+clusterPVCMountPath) {
+		mountClusterPVC(spec, claimName)
+	}
+
+	if !strings.HasPrefix(checkpointDir, "s3://") && !strings.HasPrefix(savepointDir, "s3://") {
+		return
+	}
+	if s3Endpoint != "" {
+		spec.FlinkConfiguration["s3.endpoint"] = s3Endpoint
+		spec.FlinkConfiguration["s3.path.style.access"] = "true"
+	}
+
+	if spec.PodTemplate == nil {
+		spec.PodTemplate = &v1.PodTemplateSpec{}
+	}
+	container := mainContainer(&spec.PodTemplate.Spec)
+	container.Env = append(container.Env, v1.EnvVar{
+		Name:  "ENABLE_BUILT_IN_PLUGINS",
+		Value: s3Plugin,
+	})
+	if s3CredentialsSecret != "" {
+		container.EnvFrom = append(container.EnvFrom, v1.EnvFromSource{
+			SecretRef: &v1.SecretEnvSource{
+				LocalObjectReference: v1.LocalObjectReference{Name: s3CredentialsSecret},
+			},
+		})
+	}
+}
+
+// configureExternalHarness runs a harness worker pool sidecar next to
+// every task manager. Pipelines reach it on localhost.
+func configureExternalHarness(spec *types.FlinkDeploymentSpec, claimName string) {
+	podSpec := &spec.TaskManager.PodTemplate.Spec
+	podSpec.Containers = append(podSpec.Containers, workerPoolContainer())
+	podSpec.Volumes = append(podSpec.Volumes, clusterPVCVolume(claimName))
+}
+
+// configureDockerHarness lets task managers start harness containers
+// through the docker socket of the node. This needs privileged containers
+// and nodes running docker.
+func configureDockerHarness(spec *types.FlinkDeploymentSpec, claimName string) {
+	privileged := func(b bool) *bool { return &b }(true)
+
+	spec.PodTemplate = &v1.PodTemplateSpec{
+		Spec: v1.PodSpec{
+			Containers: []v1.Container{
+				{
+					Name:            "flink-main-container",
+					Image:           *spec.Image,
+					VolumeMounts:    []v1.VolumeMount{},
+					SecurityContext: &v1.SecurityContext{Privileged: privileged},
+				},
+			},
+			Volumes: []v1.Volume{},
+		},
+	}
+
+	podSpec := &spec.TaskManager.PodTemplate.Spec
+	podSpec.Containers = append(podSpec.Containers,
+		workerPoolContainer(),
+		v1.Container{
+			Name:            "flink-main-container",
+			SecurityContext: &v1.SecurityContext{Privileged: privileged},
+			VolumeMounts: []v1.VolumeMount{
+				{
+					MountPath: "/var/run/docker.sock",
+					Name:      "docker-socket",
+				},
+			},
+		},
+	)
+	podSpec.Volumes = append(podSpec.Volumes,
+		clusterPVCVolume(claimName),
+		v1.Volume{
+			Name: "docker-socket",
+			VolumeSource: v1.VolumeSource{
+				HostPath: &v1.HostPathVolumeSource{
+					Path: "/var/run/docker.sock",
+					Type: func() *v1.HostPathType {
+						t := v1.HostPathSocket
+						return &t
+					}(),
+				},
+			},
+		},
+	)
+}
+
+// configureProcessHarness copies the beam boot binary from the harness
+// image into the task managers, which start the harness as a local process.
+func configureProcessHarness(spec *types.FlinkDeploymentSpec, claimName string) {
+	podSpec := &spec.TaskManager.PodTemplate.Spec
+	podSpec.InitContainers = append(podSpec.InitContainers, v1.Container{
+		Name:    "beam-boot",
+		Image:   types.BeamHarnessImage,
+		Command: []string{"sh", "-c"},
+		Args:    []string{fmt.Sprintf("cp -r %s/. /beam-boot/", types.BeamBootPath)},
+		VolumeMounts: []v1.VolumeMount{
+			{
+				MountPath: "/beam-boot",
+				Name:      "beam-boot",
+			},
+		},
+	})
+
+	container := mainContainer(podSpec)
+	container.VolumeMounts = append(container.VolumeMounts,
+		v1.VolumeMount{
+			MountPath: types.BeamBootPath,
+			Name:      "beam-boot",
+		},
+		v1.VolumeMount{
+			MountPath: clusterPVCMountPath,
+			Name:      "flink-cluster-pvc",
+		},
+	)
+	podSpec.Volumes = append(podSpec.Volumes,
+		v1.Volume{
+			Name: "beam-boot",
+			VolumeSource: v1.VolumeSource{
+				EmptyDir: &v1.EmptyDirVolumeSource{},
+			},
+		},
+		clusterPVCVolume(claimName),
+	)
+}
+
+func workerPoolContainer() v1.Container {
+	return v1.Container{
+		Name:  "worker",
+		Image: types.BeamHarnessImage,
+		Args:  []string{"-worker_pool"},
+		Ports: []v1.ContainerPort{
+			{
+				Name:          "harness-port",
+				ContainerPort: types.WorkerPoolPort,
+			},
+		},
+		VolumeMounts: []v1.VolumeMount{
+			{
+				MountPath: clusterPVCMountPath,
+				Name:      "flink-cluster-pvc",
+			},
+		},
+	}
+}
+
+func clusterPVCVolume(claimName string) v1.Volume {
+	return v1.Volume{
+		Name: "flink-cluster-pvc",
+		VolumeSource: v1.VolumeSource{
+			PersistentVolumeClaim: &v1.PersistentVolumeClaimVolumeSource{
+				ClaimName: claimName,
+			},
+		},
+	}
+}
+
+// mountClusterPVC mounts the cluster PVC into the flink-main-container of
+// the job manager and task manager pods. Checkpoints, savepoints and HA
+// metadata are written there by both, whatever the harness environment
+// mounts for its own containers.
+func mountClusterPVC(spec *types.FlinkDeploymentSpec, claimName string) {
+	if spec.JobManager.PodTemplate == nil {
+		spec.JobManager.PodTemplate = &v1.PodTemplateSpec{}
+	}
+	if spec.TaskManager.PodTemplate == nil {
+		spec.TaskManager.PodTemplate = &v1.PodTemplateSpec{}
+	}
+
+	for _, podSpec := range []*v1.PodSpec{&spec.JobManager.PodTemplate.Spec, &spec.TaskManager.PodTemplate.Spec} {
+		hasVolume := false
+		for _, volume := range podSpec.Volumes {
+			if volume.Name == "flink-cluster-pvc" {
+				hasVolume = true
+			}
+		}
+		if !hasVolume {
+			podSpec.Volumes = append(podSpec.Volumes, clusterPVCVolume(claimName))
+		}
+
+		container := mainContainer(podSpec)
+		mounted := false
+		for _, mount := range container.VolumeMounts {
+			if mount.Name == "flink-cluster-pvc" {
+				mounted = true
+			}
+		}
+		if !mounted {
+			container.VolumeMounts = append(container.VolumeMounts, v1.VolumeMount{
+				MountPath: clusterPVCMountPath,
+				Name:      "flink-cluster-pvc",
+			})
+		}
+	}
+}
+
+// mainContainer returns the flink-main-container of a pod template, adding it if missing.
+func mainContainer(podSpec *v1.PodSpec) *v1.Container {
+	for i := range podSpec.Containers {
+		if podSpec.Containers[i].Name == "flink-main-container" {
+			return &podSpec.Containers[i]
+		}
+	}
+	podSpec.Containers = append(podSpec.Containers, v1.Container{Name: "flink-main-container"})
+	return &podSpec.Containers[len(podSpec.Containers)-1]
 }
 
 // export ELASTIC_PASSWORD="admin"
@@ -0,0 +1,229 @@
+package create
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/BeamStackProj/beamstack-cli/src/objects"
+	"github.com/BeamStackProj/beamstack-cli/src/types"
+	"github.com/BeamStackProj/beamstack-cli/src/utils"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var (
+	kafkaLongDesc = utils.LongDesc(`
+		Create a kafka cluster running in KRaft mode, along with its topics.
+		Topics are given as NAME or NAME:PARTITIONS. The cluster is created in
+		the namespace of the strimzi operator, the only namespace it watches.
+		`)
+
+	kafkaExample = utils.Examples(`
+		# Create a single broker kafka cluster
+		beamstack create kafka my-kafka
+
+		# Create a three broker kafka cluster with two topics
+		beamstack create kafka my-kafka --brokers 3 --topics events:6,alerts
+		`)
+
+	kafkaVersion      string   = ""
+	kafkaBrokers      uint8    = 1
+	kafkaTopics       []string = []string{}
+	kafkaStorageSize  string   = "10Gi"
+	kafkaStorageClass string   = ""
+)
+
+// KafkaCmd represents the create kafka command
+var KafkaCmd = &cobra.Command{
+	Use:     "kafka [NAME]",
+	Short:   "create kafka cluster",
+	Long:    kafkaLongDesc,
+	Example: kafkaExample,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			return fmt.Errorf("kafka command requires exactly one argument: cluster Name. Provided %d arguments", len(args))
+		}
+		return nil
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		if kafkaBrokers == 0 {
+			fmt.Println("kafka cluster requires at least one broker")
+			return
+		}
+
+		topics, err := parseKafkaTopics(kafkaTopics)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		profile, err := utils.ValidateCluster()
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		operatorNamespace, ok := packageNamespace(profile, types.KafkaPackage("").Name)
+		if !ok {
+			fmt.Println("Strimzi kafka operator not initialized on this cluster. please run 'beamstack init --kafka'")
+			return
+		}
+		if operatorNamespace == "" {
+			operatorNamespace = profile.Namespace(types.ComponentKafka)
+		}
+
+		// the strimzi install manifest only watches the namespace of the operator
+		namespace := utils.WorkloadNamespace(operatorNamespace)
+		if namespace != operatorNamespace {
+			fmt.Printf("the strimzi kafka operator only watches namespace %s and would not reconcile a kafka cluster in namespace %s\n", operatorNamespace, namespace)
+			return
+		}
+
+		// internal topics cannot be replicated to more brokers than the cluster has
+		replication := min(int32(kafkaBrokers), 3)
+		minInsync := max(replication-1, 1)
+
+		err = objects.CreateDynamicResource(
+			metav1.TypeMeta{
+				APIVersion: "kafka.strimzi.io/v1beta2",
+				Kind:       "KafkaNodePool",
+			},
+			metav1.ObjectMeta{
+				Name:      fmt.Sprintf("%s-pool", args[0]),
+				Namespace: namespace,
+				Labels: utils.EnvironmentLabels(map[string]string{
+					types.KafkaClusterLabel: args[0],
+				}),
+			},
+			types.KafkaNodePoolSpec{
+				Replicas: int32(kafkaBrokers),
+				Roles:    []string{"controller", "broker"},
+				Storage: types.KafkaStorage{
+					Type: "jbod",
+					Volumes: []types.KafkaVolume{
+						{
+							Id:            0,
+							Type:          "persistent-claim",
+							Size:          kafkaStorageSize,
+							Class:         kafkaStorageClass,
+							DeleteClaim:   false,
+							KraftMetadata: "shared",
+						},
+					},
+				},
+			},
+			"kafkanodepools",
+		)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		err = objects.CreateDynamicResource(
+			metav1.TypeMeta{
+				APIVersion: "kafka.strimzi.io/v1beta2",
+				Kind:       "Kafka",
+			},
+			metav1.ObjectMeta{
+				Name:      args[0],
+				Namespace: namespace,
+				Labels:    utils.EnvironmentLabels(nil),
+				Annotations: map[string]string{
+					"strimzi.io/node-pools": "enabled",
+					"strimzi.io/kraft":      "enabled",
+				},
+			},
+			types.KafkaSpec{
+				Kafka: types.KafkaClusterSpec{
+					Version: kafkaVersion,
+					Listeners: []types.KafkaListener{
+						{
+							Name: "plain",
+							Port: 9092,
+							Type: "internal",
+							Tls:  false,
+						},
+					},
+					Config: map[string]interface{}{
+						"offsets.topic.replication.factor":         int64(replication),
+						"transaction.state.log.replication.factor": int64(replication),
+						"transaction.state.log.min.isr":            int64(minInsync),
+						"default.replication.factor":               int64(replication),
+						"min.insync.replicas":                      int64(minInsync),
+					},
+				},
+				EntityOperator: map[string]interface{}{
+					"topicOperator": map[string]interface{}{},
+					"userOperator":  map[string]interface{}{},
+				},
+			},
+			types.KafkaGVR.Resource,
+		)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		failedTopics := []string{}
+		for topic, partitions := range topics {
+			err = objects.CreateDynamicResource(
+				metav1.TypeMeta{
+					APIVersion: "kafka.strimzi.io/v1beta2",
+					Kind:       "KafkaTopic",
+				},
+				metav1.ObjectMeta{
+					Name:      topic,
+					Namespace: namespace,
+					Labels: utils.EnvironmentLabels(map[string]string{
+						types.KafkaClusterLabel: args[0],
+					}),
+				},
+				types.KafkaTopicSpec{
+					Partitions: partitions,
+					Replicas:   replication,
+				},
+				types.KafkaTopicGVR.Resource,
+			)
+			if err != nil {
+				fmt.Printf("could not create topic %s: %s\n", topic, err)
+				failedTopics = append(failedTopics, topic)
+			}
+		}
+
+		if len(failedTopics) > 0 {
+			sort.Strings(failedTopics)
+			fmt.Printf("Kafka cluster created, but topics %s could not be created\n", strings.Join(failedTopics, ", "))
+		} else {
+			fmt.Println("Kafka cluster created")
+		}
+		fmt.Printf("incluster bootstrap address: %s \n", types.KafkaBootstrapAddress(args[0], namespace))
+	},
+}
+
+func init() {
+	KafkaCmd.Flags().StringVar(&kafkaVersion, "version", kafkaVersion, "kafka version. defaults to the latest version supported by the operator")
+	KafkaCmd.Flags().Uint8Var(&kafkaBrokers, "brokers", kafkaBrokers, "number of kafka brokers")
+	KafkaCmd.Flags().StringSliceVar(&kafkaTopics, "topics", kafkaTopics, "topics to create, as NAME or NAME:PARTITIONS")
+	KafkaCmd.Flags().StringVar(&kafkaStorageSize, "storage-size", kafkaStorageSize, "size of the volume of each broker")
+	KafkaCmd.Flags().StringVar(&kafkaStorageClass, "storage-class", kafkaStorageClass, "storage class of the broker volumes. The cluster default class is used if not provided")
+}
+
+func parseKafkaTopics(topics []string) (map[string]int32, error) {
+	parsed := map[string]int32{}
+	for _, topic := range topics {
+		name, partitions, found := strings.Cut(topic, ":")
+		if name == "" {
+			return nil, fmt.Errorf("invalid topic %q, expected NAME or NAME:PARTITIONS", topic)
+		}
+		parsed[name] = 1
+		if found {
+			count, err := strconv.ParseInt(partitions, 10, 32)
+			if err != nil || count < 1 {
+				return nil, fmt.Errorf("invalid partition count in topic %q", topic)
+			}
+			parsed[name] = int32(count)
+		}
+	}
+	return parsed, nil
+}
@@ -4,13 +4,18 @@ Copyright © 2024 MavenCode <opensource-dev@mavencode.com>
 package deploy
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"path"
+	"strconv"
 	"strings"
 	"time"
 
 	"path/filepath"
+	"slices"
 
+	info_handler "github.com/BeamStackProj/beamstack-cli/src/handlers/info"
 	"github.com/BeamStackProj/beamstack-cli/src/objects"
 	"github.com/BeamStackProj/beamstack-cli/src/types"
 	"github.com/BeamStackProj/beamstack-cli/src/utils"
@@ -18,6 +23,7 @@ import (
 	"gopkg.in/yaml.v2"
 	batchv1 "k8s.io/api/batch/v1"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/kubernetes"
@@ -40,6 +46,19 @@ var (
 	config                *rest.Config = utils.GetKubeConfig()
 	pipelineFilename      string
 	CleanPipelineFilename string
+	schedulingFlags       utils.SchedulingFlags
+	ignoreCapacity        bool = false
+	harness               string
+	storage               string = storagePVC
+	bucket                string = "beamstack"
+)
+
+const (
+	storagePVC = "pvc"
+	storageS3  = "s3"
+
+	// staging directory of migration pods copying data to and from the object store
+	stagingPath = "/staging"
 )
 
 type FileInfo struct {
@@ -69,6 +88,12 @@ func init() {
 	PipelineCmd.Flags().BoolVarP(&Wait, "wait", "w", Wait, "Wait for the pipeline to complete.")
 	PipelineCmd.Flags().BoolVarP(&Migrate, "migrate", "m", Migrate, "Migrate data to the Kubernetes cluster. This is necessary if the pipeline is to be run on local data. Pipeline Results will also be migrated to local system if wait is true.")
 
+	PipelineCmd.Flags().StringVar(&harness, "environment", harness, "SDK harness environment of the pipeline. One of external, docker, process or loopback. Defaults to the environment the flink cluster was created for. loopback runs the harness next to the pipeline job and works on every cluster.")
+	utils.AddSchedulingFlags(PipelineCmd.Flags(), &schedulingFlags)
+	PipelineCmd.Flags().StringVar(&storage, "storage", storage, "Where pipeline data is staged. One of pvc or s3. s3 uses the object store installed with 'beamstack init --object-store'.")
+	PipelineCmd.Flags().StringVar(&bucket, "bucket", bucket, "Object store bucket pipeline data is staged in. Ignored unless storage is s3. Created if it does not exist.")
+	PipelineCmd.Flags().BoolVar(&ignoreCapacity, "ignore-capacity", ignoreCapacity, "Deploy the pipeline even if the cluster lacks the resources for the task managers it needs.")
+
 	PipelineCmd.MarkFlagRequired("flink")
 }
 
@@ -86,6 +111,43 @@ func DeployPipeline(cmd *cobra.Command, args []string) {
 		fmt.Println("Flink Operator not initialized on this cluster")
 		return
 	}
+
+	namespace := utils.WorkloadNamespace(profile.Namespace(types.ComponentFlink))
+
+	cluster, err := objects.GetFlinkCluster(profile, flinkCluster, namespace)
+	if err != nil {
+		fmt.Printf("could not find flink cluster %s: %s\n", flinkCluster, err)
+		return
+	}
+
+	pipelineHarness := harness
+	if pipelineHarness == "" {
+		pipelineHarness = cluster.Harness()
+	}
+	if !slices.Contains(types.Harnesses, pipelineHarness) {
+		fmt.Printf("unsupported harness environment %s. use one of %s\n", pipelineHarness, strings.Join(types.Harnesses, ", "))
+		return
+	}
+	if !harnessSupported(cluster.Harness(), pipelineHarness) {
+		fmt.Printf("flink cluster %s was created for the %s harness environment and cannot run %s pipelines\n", flinkCluster, cluster.Harness(), pipelineHarness)
+		return
+	}
+
+	scheduling, err := schedulingFlags.Resolve(profile.Scheduling)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	if storage != storagePVC && storage != storageS3 {
+		fmt.Printf("unsupported storage %s. use pvc or s3\n", storage)
+		return
+	}
+	if storage == storageS3 && profile.ObjectStore == nil {
+		fmt.Println("Object store not initialized on this cluster")
+		return
+	}
+
 	pipeline := &types.Pipeline{}
 	err = utils.ParseYAML(pipelineFilename, pipeline)
 	if err != nil {
@@ -103,45 +165,70 @@ func DeployPipeline(cmd *cobra.Command, args []string) {
 		return
 	}
 
-	MigrationPod, err := objects.CreatePod(clientset,
-		v1.Pod{
-			ObjectMeta: metav1.ObjectMeta{
-				Name:      fmt.Sprintf("%s-migration", JobName),
-				Namespace: "flink",
-			},
-			Spec: v1.PodSpec{
-				Containers: []v1.Container{
-					{
-						Name:  "busybox",
-						Image: "busybox",
-						Command: []string{
-							"sh",
-						},
-						Args: []string{
-							"-c",
-							`while true; do echo \"Running Migration!\"; sleep 3600; done`,
-						},
-						VolumeMounts: []v1.VolumeMount{
-							{
-								Name:      "migration-volume",
-								MountPath: PVCMountPath,
-							},
+	if err := checkPipelineCapacity(clientset, cluster, scheduling); err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	wiring, err := resolveClusterReferences(clientset, pipeline, profile, namespace, utils.WorkloadNamespace(profile.Namespace(types.ComponentKafka)))
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	// init creates the object store credentials in the namespace of the flink operator only
+	if storage == storageS3 && namespace != profile.Namespace(types.ComponentFlink) {
+		store := profile.ObjectStore
+		if err := objects.CopySecret(clientset, store.CredentialsSecret, store.Namespace, store.CredentialsSecret, namespace); err != nil {
+			fmt.Printf("could not copy the object store credentials to namespace %s: %s\n", namespace, err)
+			return
+		}
+	}
+
+	migrationPod := v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-migration", JobName),
+			Namespace: namespace,
+			Labels:    utils.EnvironmentLabels(nil),
+		},
+		Spec: v1.PodSpec{
+			Containers: []v1.Container{
+				{
+					Name:  "busybox",
+					Image: "busybox",
+					Command: []string{
+						"sh",
+					},
+					Args: []string{
+						"-c",
+						`while true; do echo \"Running Migration!\"; sleep 3600; done`,
+					},
+					VolumeMounts: []v1.VolumeMount{
+						{
+							Name:      "migration-volume",
+							MountPath: PVCMountPath,
 						},
 					},
 				},
-				Volumes: []v1.Volume{
-					{
-						Name: "migration-volume",
-						VolumeSource: v1.VolumeSource{
-							PersistentVolumeClaim: &v1.PersistentVolumeClaimVolumeSource{
-								ClaimName: fmt.Sprintf("%s-pvc", flinkCluster),
-							},
+			},
+			Volumes: []v1.Volume{
+				{
+					Name: "migration-volume",
+					VolumeSource: v1.VolumeSource{
+						PersistentVolumeClaim: &v1.PersistentVolumeClaimVolumeSource{
+							ClaimName: cluster.ClaimName(),
 						},
 					},
 				},
 			},
-		})
+		},
+	}
+	if storage == storageS3 {
+		configureObjectStoreMigration(&migrationPod.Spec, *profile.ObjectStore)
+	}
+	scheduling.Apply(&migrationPod.Spec)
 
+	MigrationPod, err := objects.CreatePod(clientset, migrationPod)
 	if err != nil {
 		fmt.Println(err)
 		return
@@ -160,12 +247,12 @@ func DeployPipeline(cmd *cobra.Command, args []string) {
 
 				if !fileInfo.IsDir() {
 					splits := strings.Split(path, "/")
-					(*src.Config)["path"] = filepath.Join(PVCMountPath, "data", splits[len(splits)-1])
+					(*src.Config)["path"] = storagePath("data", splits[len(splits)-1])
 				} else {
-					(*src.Config)["path"] = filepath.Join(PVCMountPath, "data")
+					(*src.Config)["path"] = storagePath("data")
 				}
 
-				uploadList = append(uploadList, FileInfo{Src: path, Dest: filepath.Join(PVCMountPath, "data")})
+				uploadList = append(uploadList, FileInfo{Src: path, Dest: filepath.Join(migrationRoot(), "data")})
 			}
 		}
 
@@ -180,9 +267,9 @@ func DeployPipeline(cmd *cobra.Command, args []string) {
 					resultPath = filepath.Join(resultsFolder, splits[len(splits)-1])
 				}
 
-				(*sink.Config)["path"] = filepath.Join(PVCMountPath, resultPath)
+				(*sink.Config)["path"] = storagePath(resultPath)
 
-				downloadList = append(downloadList, FileInfo{Src: filepath.Join(PVCMountPath, resultsFolder), Dest: path})
+				downloadList = append(downloadList, FileInfo{Src: filepath.Join(migrationRoot(), resultsFolder), Dest: path})
 			}
 		}
 
@@ -198,12 +285,12 @@ func DeployPipeline(cmd *cobra.Command, args []string) {
 
 					if !fileInfo.IsDir() {
 						splits := strings.Split(path, "/")
-						(*tf.Config)["path"] = filepath.Join(PVCMountPath, "data", splits[len(splits)-1])
+						(*tf.Config)["path"] = storagePath("data", splits[len(splits)-1])
 					} else {
-						(*tf.Config)["path"] = filepath.Join(PVCMountPath, "data")
+						(*tf.Config)["path"] = storagePath("data")
 					}
 
-					uploadList = append(uploadList, FileInfo{Src: path, Dest: filepath.Join(PVCMountPath, "data")})
+					uploadList = append(uploadList, FileInfo{Src: path, Dest: filepath.Join(migrationRoot(), "data")})
 				}
 
 			} else if strings.HasPrefix(strings.ToLower(tf.Type), "writeto") {
@@ -217,7 +304,7 @@ func DeployPipeline(cmd *cobra.Command, args []string) {
 						resultPath = filepath.Join(resultsFolder, splits[len(splits)-1])
 					}
 
-					(*tf.Config)["path"] = filepath.Join(PVCMountPath, resultPath)
+					(*tf.Config)["path"] = storagePath(resultPath)
 					hasResuls = true
 				}
 			}
@@ -230,7 +317,7 @@ func DeployPipeline(cmd *cobra.Command, args []string) {
 				fmt.Println("Error creating directory:", err)
 				return
 			}
-			downloadList = append(downloadList, FileInfo{Src: filepath.Join(PVCMountPath, resultsFolder), Dest: outDir})
+			downloadList = append(downloadList, FileInfo{Src: filepath.Join(migrationRoot(), resultsFolder), Dest: outDir})
 		}
 
 		fmt.Println("Performing data migration!")
@@ -247,6 +334,18 @@ func DeployPipeline(cmd *cobra.Command, args []string) {
 			}
 		}
 
+		if storage == storageS3 && len(uploadList) > 0 {
+			for _, command := range [][]string{
+				types.MinioClientCommand("mb", "--ignore-existing", objectStorePath()),
+				types.MinioClientCommand("cp", "--recursive", filepath.Join(stagingPath, "data")+"/", objectStorePath("data")+"/"),
+			} {
+				if err := utils.ExecInContainer(clientset, *MigrationPod, "mc", command); err != nil {
+					fmt.Printf("could not upload pipeline data to the object store: %s\n", err)
+					return
+				}
+			}
+		}
+
 		pipelineFilename, err = savePipeline(pipeline)
 
 		if err != nil {
@@ -258,9 +357,27 @@ func DeployPipeline(cmd *cobra.Command, args []string) {
 
 		CleanPipelineFilename = splits[len(splits)-1]
 
+	} else if wiring.Resolved > 0 {
+		pipelineFilename, err = savePipeline(pipeline)
+
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		CleanPipelineFilename = filepath.Base(pipelineFilename)
 	}
 
-	if err := utils.MigrateFilesToContainer(
+	pipelineSpecArg := fmt.Sprintf("--pipeline_spec_file=%s", filepath.Join(PVCMountPath, CleanPipelineFilename))
+	if storage == storageS3 {
+		// without a shared volume the pipeline spec is passed inline
+		spec, err := os.ReadFile(pipelineFilename)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		pipelineSpecArg = fmt.Sprintf("--pipeline_spec=%s", spec)
+	} else if err := utils.MigrateFilesToContainer(
 		clientset,
 		types.MigrationParams{
 			Pod:      *MigrationPod,
@@ -272,54 +389,50 @@ func DeployPipeline(cmd *cobra.Command, args []string) {
 		return
 	}
 	BackOffLimit := int32(1)
-	pipelineJob, err := objects.CreateJob(
-		clientset,
-		batchv1.Job{
-			ObjectMeta: metav1.ObjectMeta{
-				Name:      JobName,
-				Namespace: "flink",
-			},
-			Spec: batchv1.JobSpec{
-				BackoffLimit: &BackOffLimit,
-				Template: v1.PodTemplateSpec{
-					ObjectMeta: metav1.ObjectMeta{
-						Labels: map[string]string{"app": JobName},
-					},
-					Spec: v1.PodSpec{
-						RestartPolicy: "Never",
-						Containers: []v1.Container{
-							{
-								Name:  "beam-pipeline",
-								Image: "beamstackproj/beam-harness:latest",
-								Command: []string{"python"},
-								Args: []string{
-									"-m",
-									"apache_beam.yaml.main",
-									fmt.Sprintf("--pipeline_spec_file=%s", filepath.Join(PVCMountPath, CleanPipelineFilename)),
-									"--runner=FlinkRunner",
-									fmt.Sprintf("--flink_master=%s-rest.flink.svc.cluster.local:8081", flinkCluster),
-									fmt.Sprintf("--job_name=%s", JobName),
-									fmt.Sprintf("--parallelism=%s", fmt.Sprintf("%d", Parallelism)),
-									"--environment_type=EXTERNAL",
-									"--environment_config=localhost:50000",
-									"--flink_submit_uber_jar",
-									"--checkpointing_interval=10000",
-								},
-								VolumeMounts: []v1.VolumeMount{
-									{
-										Name:      "migration-volume",
-										MountPath: PVCMountPath,
-									},
+	job := batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      JobName,
+			Namespace: namespace,
+			Labels:    utils.EnvironmentLabels(nil),
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: &BackOffLimit,
+			Template: v1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: utils.EnvironmentLabels(map[string]string{"app": JobName}),
+				},
+				Spec: v1.PodSpec{
+					RestartPolicy: "Never",
+					Containers: []v1.Container{
+						{
+							Name:    "beam-pipeline",
+							Image:   types.BeamHarnessImage,
+							Command: []string{"python"},
+							Args: []string{
+								"-m",
+								"apache_beam.yaml.main",
+								pipelineSpecArg,
+								"--runner=FlinkRunner",
+								fmt.Sprintf("--flink_master=%s-rest.%s.svc.cluster.local:8081", flinkCluster, namespace),
+								fmt.Sprintf("--job_name=%s", JobName),
+								fmt.Sprintf("--parallelism=%s", fmt.Sprintf("%d", Parallelism)),
+								"--flink_submit_uber_jar",
+								"--checkpointing_interval=10000",
+							},
+							VolumeMounts: []v1.VolumeMount{
+								{
+									Name:      "migration-volume",
+									MountPath: PVCMountPath,
 								},
 							},
 						},
-						Volumes: []v1.Volume{
-							{
-								Name: "migration-volume",
-								VolumeSource: v1.VolumeSource{
-									PersistentVolumeClaim: &v1.PersistentVolumeClaimVolumeSource{
-										ClaimName: fmt.Sprintf("%s-pvc", flinkCluster),
-									},
+					},
+					Volumes: []v1.Volume{
+						{
+							Name: "migration-volume",
+							VolumeSource: v1.VolumeSource{
+								PersistentVolumeClaim: &v1.PersistentVolumeClaimVolumeSource{
+									ClaimName: cluster.ClaimName(),
 								},
 							},
 						},
@@ -327,8 +440,18 @@ func DeployPipeline(cmd *cobra.Command, args []string) {
 				},
 			},
 		},
-	)
+	}
+	if storage == storageS3 {
+		configureObjectStore(&job.Spec.Template.Spec, *profile.ObjectStore)
+	}
+	if err := wiring.apply(&job.Spec.Template.Spec); err != nil {
+		fmt.Println(err)
+		return
+	}
+	configureHarness(&job.Spec.Template.Spec, pipelineHarness)
+	scheduling.Apply(&job.Spec.Template.Spec)
 
+	pipelineJob, err := objects.CreateJob(clientset, job)
 	if err != nil {
 		fmt.Printf("could not create pipeline job %s\n", err)
 		return
@@ -348,7 +471,7 @@ func DeployPipeline(cmd *cobra.Command, args []string) {
 			Group:    "batch",
 			Version:  "v1",
 			Resource: "jobs",
-		}, pipelineJob.Name, "flink", "Complete", donChan)
+		}, pipelineJob.Name, namespace, "Complete", donChan)
 
 		for i := range donChan {
 			fmt.Println(i)
@@ -356,6 +479,12 @@ func DeployPipeline(cmd *cobra.Command, args []string) {
 
 		if Migrate && downloadList != nil {
 			fmt.Println("migrating pipeline results!")
+			if storage == storageS3 {
+				command := types.MinioClientCommand("cp", "--recursive", objectStorePath(resultsFolder)+"/", filepath.Join(stagingPath, resultsFolder)+"/")
+				if err := utils.ExecInContainer(clientset, *MigrationPod, "mc", command); err != nil {
+					fmt.Printf("could not download pipeline results from the object store: %s\n", err)
+				}
+			}
 			for _, path := range downloadList {
 				utils.MigrateFilesFromContainer(clientset,
 					types.MigrationParams{
@@ -371,12 +500,191 @@ func DeployPipeline(cmd *cobra.Command, args []string) {
 		fmt.Println("Pipeline is done!")
 		fg := metav1.DeletePropagationBackground
 
-		clientset.BatchV1().Jobs("flink").Delete(cmd.Context(), pipelineJob.Name, metav1.DeleteOptions{PropagationPolicy: &fg})
+		clientset.BatchV1().Jobs(namespace).Delete(cmd.Context(), pipelineJob.Name, metav1.DeleteOptions{PropagationPolicy: &fg})
 	}
 
 	fg := metav1.DeletePropagationBackground
-	clientset.CoreV1().Pods("flink").Delete(cmd.Context(), MigrationPod.Name, metav1.DeleteOptions{PropagationPolicy: &fg})
+	clientset.CoreV1().Pods(namespace).Delete(cmd.Context(), MigrationPod.Name, metav1.DeleteOptions{PropagationPolicy: &fg})
+
+}
+
+// storagePath returns where the pipeline reads or writes the path relative to
+// the configured storage, either on the cluster PVC or in the object store bucket.
+func storagePath(elem ...string) string {
+	if storage == storageS3 {
+		return "s3://" + path.Join(append([]string{bucket}, elem...)...)
+	}
+	return filepath.Join(append([]string{PVCMountPath}, elem...)...)
+}
+
+// objectStorePath returns the path in the bucket as seen by the minio client.
+func objectStorePath(elem ...string) string {
+	return path.Join(append([]string{types.ObjectStoreAlias, bucket}, elem...)...)
+}
+
+// migrationRoot returns the directory of the migration pod data is copied to and from.
+func migrationRoot() string {
+	if storage == storageS3 {
+		return stagingPath
+	}
+	return PVCMountPath
+}
+
+// configureObjectStoreMigration replaces the PVC of the migration pod with a
+// staging directory shared with a minio client container, which copies the
+// staged data to and from the object store.
+func configureObjectStoreMigration(podSpec *v1.PodSpec, store types.ObjectStore) {
+	podSpec.Volumes[0].VolumeSource = v1.VolumeSource{EmptyDir: &v1.EmptyDirVolumeSource{}}
+	podSpec.Containers[0].VolumeMounts[0].MountPath = stagingPath
+
+	podSpec.Containers = append(podSpec.Containers, v1.Container{
+		Name:    "mc",
+		Image:   types.MinioClientImage,
+		Command: []string{"sleep", "infinity"},
+		Env:     store.CredentialsEnv(),
+		VolumeMounts: []v1.VolumeMount{
+			{
+				Name:      podSpec.Volumes[0].Name,
+				MountPath: stagingPath,
+			},
+		},
+	})
+}
+
+// configureObjectStore drops the PVC from the pipeline job and passes the
+// object store endpoint to the pipeline as an s3 option. The s3 filesystem
+// reads the credentials from the environment, keeping them out of the
+// pipeline options flink logs and shows.
+func configureObjectStore(podSpec *v1.PodSpec, store types.ObjectStore) {
+	podSpec.Volumes = nil
+
+	container := &podSpec.Containers[0]
+	container.VolumeMounts = nil
+	container.Env = append(container.Env, store.CredentialsEnv()...)
+	container.Args = append(container.Args, fmt.Sprintf("--s3_endpoint_url=%s", store.Endpoint))
+}
+
+// harnessSupported reports whether a cluster created for one SDK harness
+// environment can run pipelines in another. Docker clusters keep the worker
+// pool sidecar and loopback pipelines bring their own harness.
+func harnessSupported(clusterHarness string, pipelineHarness string) bool {
+	return clusterHarness == pipelineHarness ||
+		pipelineHarness == types.HarnessLoopback ||
+		(clusterHarness == types.HarnessDocker && pipelineHarness == types.HarnessExternal)
+}
+
+// configureEnvironment adds the pipeline options selecting the SDK harness
+// environment to the pipeline container. Loopback pipelines also get a worker
+// pool sidecar the task managers reach through the pod IP.
+func configureHarness(podSpec *v1.PodSpec, harness string) {
+	container := &podSpec.Containers[0]
+
+	switch harness {
+	case types.HarnessDocker:
+		container.Args = append(container.Args,
+			"--environment_type=DOCKER",
+			fmt.Sprintf("--environment_config=%s", types.BeamHarnessImage),
+		)
+	case types.HarnessProcess:
+		container.Args = append(container.Args,
+			"--environment_type=PROCESS",
+			fmt.Sprintf(`--environment_config={"command":"%s/boot"}`, types.BeamBootPath),
+		)
+	case types.HarnessLoopback:
+		container.Env = append(container.Env, v1.EnvVar{
+			Name: "POD_IP",
+			ValueFrom: &v1.EnvVarSource{
+				FieldRef: &v1.ObjectFieldSelector{FieldPath: "status.podIP"},
+			},
+		})
+		container.Args = append(container.Args,
+			"--environment_type=EXTERNAL",
+			fmt.Sprintf("--environment_config=$(POD_IP):%d", types.WorkerPoolPort),
+		)
+
+		// a native sidecar stops with the pipeline container so the job can complete
+		restartPolicy := v1.ContainerRestartPolicyAlways
+		podSpec.InitContainers = append(podSpec.InitContainers, v1.Container{
+			Name:          "worker",
+			Image:         types.BeamHarnessImage,
+			Args:          []string{"-worker_pool"},
+			RestartPolicy: &restartPolicy,
+			Ports: []v1.ContainerPort{
+				{
+					Name:          "harness-port",
+					ContainerPort: types.WorkerPoolPort,
+				},
+			},
+			VolumeMounts: container.VolumeMounts,
+		})
+	default:
+		container.Args = append(container.Args,
+			"--environment_type=EXTERNAL",
+			fmt.Sprintf("--environment_config=localhost:%d", types.WorkerPoolPort),
+		)
+	}
+}
 
+// checkPipelineCapacity refuses to deploy a pipeline when the task managers
+// the cluster has to start for its parallelism cannot be scheduled.
+func checkPipelineCapacity(clientset *kubernetes.Clientset, cluster types.FlinkDeployment, scheduling types.Scheduling) error {
+	slots, err := strconv.Atoi(cluster.Spec.FlinkConfiguration["taskmanager.numberOfTaskSlots"])
+	if err != nil || slots < 1 {
+		slots = 1
+	}
+	taskManagers := (int(Parallelism) + slots - 1) / slots
+
+	running, err := clientset.CoreV1().Pods(cluster.Namespace).List(context.TODO(), metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("app=%s,component=taskmanager", cluster.Name),
+	})
+	if err == nil {
+		taskManagers -= len(running.Items)
+	}
+	if taskManagers <= 0 {
+		return nil
+	}
+
+	// the operator falls back to the flink defaults for unset task manager resources
+	cpu, memory := cluster.Spec.TaskManager.Resource.CPU, cluster.Spec.TaskManager.Resource.Memory
+	if cpu == "" {
+		cpu = "1"
+	}
+	if memory == "" {
+		memory = "1728Mi"
+	}
+	cpuQuantity, err := resource.ParseQuantity(cpu)
+	if err != nil {
+		return fmt.Errorf("invalid task manager cpu %s: %s", cpu, err)
+	}
+	memoryQuantity, err := resource.ParseQuantity(memory)
+	if err != nil {
+		return fmt.Errorf("invalid task manager memory %s: %s", memory, err)
+	}
+
+	pods := []v1.ResourceList{}
+	for i := 0; i < taskManagers; i++ {
+		pods = append(pods, v1.ResourceList{
+			v1.ResourceCPU:    cpuQuantity,
+			v1.ResourceMemory: memoryQuantity,
+		})
+	}
+
+	report, err := info_handler.CheckCapacity(pods, scheduling)
+	if err != nil {
+		fmt.Printf("could not check cluster capacity: %s\n", err)
+		return nil
+	}
+	if report.Fits() {
+		return nil
+	}
+
+	fmt.Printf("the %d task manager(s) needed for parallelism %d do not fit on the kubernetes cluster:\n", taskManagers, Parallelism)
+	info_handler.PrintCapacityReport(report)
+	if ignoreCapacity {
+		fmt.Println("deploying anyway, task managers may stay pending")
+		return nil
+	}
+	return fmt.Errorf("aborting. use --ignore-capacity to deploy the pipeline anyway")
 }
 
 func savePipeline(data interface{}) (string, error) {
@@ -0,0 +1,212 @@
+package deploy
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/BeamStackProj/beamstack-cli/src/objects"
+	"github.com/BeamStackProj/beamstack-cli/src/types"
+	"github.com/BeamStackProj/beamstack-cli/src/utils"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// clusterWiring holds what the pipeline job needs for the clusters the
+// pipeline references: environment variables read from credential secrets,
+// volumes holding CA certificates and the jinja variables of the pipeline
+// spec expanding the variables.
+type clusterWiring struct {
+	Resolved       int
+	Env            []v1.EnvVar
+	Volumes        []v1.Volume
+	VolumeMounts   []v1.VolumeMount
+	JinjaVariables map[string]string
+}
+
+// esCAPath is the directory the CA certificates of elasticsearch clusters
+// are mounted under in the pipeline job.
+const esCAPath = "/etc/beamstack/elasticsearch"
+
+// resolveClusterReferences rewrites the config of elasticsearch and kafka
+// transforms referencing a beamstack created cluster through a `cluster` key,
+// given as NAME or NAMESPACE/NAME, into its connection details. The pipeline
+// job runs in namespace, and kafka clusters given by name are looked up in
+// kafkaNamespace.
+// Clusters of other environments than the one of the profile are refused.
+func resolveClusterReferences(clientset *kubernetes.Clientset, pipeline *types.Pipeline, profile types.Profiles, namespace string, kafkaNamespace string) (clusterWiring, error) {
+	wiring := clusterWiring{JinjaVariables: map[string]string{}}
+
+	type transformConfig struct {
+		Type   string
+		Config *map[string]interface{}
+	}
+	configs := []transformConfig{}
+	if src := pipeline.Pipeline.Source; src != nil && src.Config != nil {
+		configs = append(configs, transformConfig{src.Type, src.Config})
+	}
+	if sink := pipeline.Pipeline.Sink; sink != nil && sink.Config != nil {
+		configs = append(configs, transformConfig{sink.Type, sink.Config})
+	}
+	for _, tf := range pipeline.Pipeline.Transforms {
+		if tf.Config != nil {
+			configs = append(configs, transformConfig{tf.Type, tf.Config})
+		}
+	}
+
+	for _, tf := range configs {
+		reference, ok := (*tf.Config)["cluster"].(string)
+		if !ok {
+			continue
+		}
+
+		var err error
+		switch transformType := strings.ToLower(tf.Type); {
+		case strings.Contains(transformType, "elasticsearch"):
+			err = wireElasticsearch(clientset, profile, reference, namespace, *tf.Config, &wiring)
+		case strings.Contains(transformType, "kafka"):
+			err = wireKafka(profile, reference, kafkaNamespace, *tf.Config)
+		default:
+			continue
+		}
+		if err != nil {
+			return wiring, err
+		}
+		delete(*tf.Config, "cluster")
+		wiring.Resolved += 1
+	}
+
+	return wiring, nil
+}
+
+func wireElasticsearch(clientset *kubernetes.Clientset, profile types.Profiles, reference string, jobNamespace string, config map[string]interface{}, wiring *clusterWiring) error {
+	namespace, name := splitClusterReference(reference, utils.WorkloadNamespace("default"))
+
+	var cluster types.Elasticsearch
+	if err := objects.GetDynamicResource(types.EsGVR, name, namespace, &cluster); err != nil {
+		return fmt.Errorf("could not find elasticsearch cluster %s/%s: %s", namespace, name, err)
+	}
+	if !profile.Owns(cluster.Labels) {
+		return fmt.Errorf("elasticsearch cluster %s/%s belongs to environment %s", namespace, name, cluster.Labels[types.ProfileEnvironmentLabel])
+	}
+
+	// the pipeline job reads the password from a copy of the secret in its own namespace
+	secret, err := jobSecret(clientset, types.EsCredentialsSecret(name), namespace, jobNamespace)
+	if err != nil {
+		return fmt.Errorf("could not copy the credentials of elasticsearch cluster %s/%s: %s", namespace, name, err)
+	}
+
+	variable := fmt.Sprintf("ES_%s_%s_PASSWORD", envName(namespace), envName(name))
+	wiring.Env = append(wiring.Env, v1.EnvVar{
+		Name: variable,
+		ValueFrom: &v1.EnvVarSource{
+			SecretKeyRef: &v1.SecretKeySelector{
+				LocalObjectReference: v1.LocalObjectReference{Name: secret},
+				Key:                  "elastic",
+			},
+		},
+	})
+	wiring.JinjaVariables[strings.ToLower(variable)] = fmt.Sprintf("$(%s)", variable)
+
+	config["url"] = types.EsURL(name, namespace, cluster.Spec.TLS())
+	config["username"] = "elastic"
+	config["password"] = fmt.Sprintf("{{ %s }}", strings.ToLower(variable))
+
+	// ECK serves https with a self-signed certificate, verified against its CA
+	if cluster.Spec.TLS() {
+		caSecret, err := jobSecret(clientset, types.EsCASecret(name), namespace, jobNamespace)
+		if err != nil {
+			return fmt.Errorf("could not copy the CA certificate of elasticsearch cluster %s/%s: %s", namespace, name, err)
+		}
+		config["ca_certs"] = wiring.mountCA(caSecret, fmt.Sprintf("%s/%s-%s", esCAPath, namespace, name))
+	}
+	return nil
+}
+
+// jobSecret returns the name of a secret of namespace in the namespace of the
+// pipeline job, copying it there when the namespaces differ.
+func jobSecret(clientset *kubernetes.Clientset, secret string, namespace string, jobNamespace string) (string, error) {
+	if namespace == jobNamespace {
+		return secret, nil
+	}
+	copied := fmt.Sprintf("%s-%s", namespace, secret)
+	if err := objects.CopySecret(clientset, secret, namespace, copied, jobNamespace); err != nil {
+		return "", err
+	}
+	return copied, nil
+}
+
+// mountCA mounts the ca.crt of a secret in the directory and returns the path
+// of the certificate. A secret referenced by several transforms is mounted once.
+func (w *clusterWiring) mountCA(secret string, directory string) string {
+	path := fmt.Sprintf("%s/ca.crt", directory)
+	for _, mount := range w.VolumeMounts {
+		if mount.MountPath == directory {
+			return path
+		}
+	}
+
+	volume := fmt.Sprintf("ca-%d", len(w.Volumes))
+	w.Volumes = append(w.Volumes, v1.Volume{
+		Name: volume,
+		VolumeSource: v1.VolumeSource{
+			Secret: &v1.SecretVolumeSource{
+				SecretName: secret,
+				Items:      []v1.KeyToPath{{Key: "ca.crt", Path: "ca.crt"}},
+			},
+		},
+	})
+	w.VolumeMounts = append(w.VolumeMounts, v1.VolumeMount{
+		Name:      volume,
+		MountPath: directory,
+		ReadOnly:  true,
+	})
+	return path
+}
+
+func wireKafka(profile types.Profiles, reference string, kafkaNamespace string, config map[string]interface{}) error {
+	namespace, name := splitClusterReference(reference, kafkaNamespace)
+
+	var cluster types.Kafka
+	if err := objects.GetDynamicResource(types.KafkaGVR, name, namespace, &cluster); err != nil {
+		return fmt.Errorf("could not find kafka cluster %s/%s: %s", namespace, name, err)
+	}
+	if !profile.Owns(cluster.Labels) {
+		return fmt.Errorf("kafka cluster %s/%s belongs to environment %s", namespace, name, cluster.Labels[types.ProfileEnvironmentLabel])
+	}
+
+	config["bootstrap_servers"] = types.KafkaBootstrapAddress(name, namespace)
+	return nil
+}
+
+// apply adds the credentials and CA certificates to the pipeline container
+// and passes the credentials to the pipeline as jinja variables. The kubelet
+// expands the $(VAR) references in the pipeline option.
+func (w clusterWiring) apply(podSpec *v1.PodSpec) error {
+	podSpec.Volumes = append(podSpec.Volumes, w.Volumes...)
+	podSpec.Containers[0].VolumeMounts = append(podSpec.Containers[0].VolumeMounts, w.VolumeMounts...)
+	if len(w.JinjaVariables) == 0 {
+		return nil
+	}
+
+	variables, err := json.Marshal(w.JinjaVariables)
+	if err != nil {
+		return err
+	}
+
+	container := &podSpec.Containers[0]
+	container.Env = append(container.Env, w.Env...)
+	container.Args = append(container.Args, fmt.Sprintf("--jinja_variables=%s", variables))
+	return nil
+}
+
+func splitClusterReference(reference string, defaultNamespace string) (string, string) {
+	if namespace, name, found := strings.Cut(reference, "/"); found {
+		return namespace, name
+	}
+	return defaultNamespace, reference
+}
+
+func envName(name string) string {
+	return strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(name))
+}
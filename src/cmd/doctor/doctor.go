@@ -0,0 +1,78 @@
+/*
+Copyright © 2024 MavenCode <opensource-dev@mavencode.com>
+*/
+package doctor
+
+import (
+	"fmt"
+
+	doctor_handler "github.com/BeamStackProj/beamstack-cli/src/handlers/doctor"
+	"github.com/BeamStackProj/beamstack-cli/src/types"
+	"github.com/BeamStackProj/beamstack-cli/src/utils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	doctorLongDesc = utils.LongDesc(`
+		Check that the current cluster can run the components 'beamstack init'
+		installs, without installing anything. The same checks run at the start of
+		init. Checks cover the kubernetes version, the permissions to create custom
+		resource definitions, namespaces and cluster roles, storage classes, node
+		capacity and existing installs of cert-manager, the prometheus operator and
+		the elasticsearch operator, which init offers to adopt.
+		`)
+
+	doctorExample = utils.Examples(`
+		# Check the cluster for an init with flink only
+		beamstack doctor
+
+		# Check the cluster for an init with the monitoring stack and elasticsearch
+		beamstack doctor -me
+		`)
+
+	monitoring    bool = false
+	elasticsearch bool = false
+	kafka         bool = false
+	objectStore   bool = false
+)
+
+// DoctorCmd represents the doctor command
+var DoctorCmd = &cobra.Command{
+	Use:     "doctor",
+	Short:   "Run pre-flight checks on cluster",
+	Long:    doctorLongDesc,
+	Example: doctorExample,
+	Args:    cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		selected := map[string]bool{
+			types.ComponentCertManager:   true,
+			types.ComponentFlink:         true,
+			types.ComponentMonitoring:    monitoring,
+			types.ComponentElasticsearch: elasticsearch,
+			types.ComponentKafka:         kafka,
+			types.ComponentObjectStore:   objectStore,
+		}
+
+		components := []types.Component{}
+		for _, component := range types.Components {
+			if selected[component.Name] {
+				components = append(components, component)
+			}
+		}
+
+		report := doctor_handler.Preflight(components)
+		doctor_handler.PrintPreflightReport(report)
+		if report.Failed() {
+			fmt.Println("\ncluster is not ready for beamstack init")
+			return
+		}
+		fmt.Println("\ncluster is ready for beamstack init")
+	},
+}
+
+func init() {
+	DoctorCmd.Flags().BoolVarP(&monitoring, "monitoring", "m", monitoring, "If specified, check for the prometheus + grafana stack")
+	DoctorCmd.Flags().BoolVarP(&elasticsearch, "elasticsearch", "e", elasticsearch, "If specified, check for the elasticsearch operator")
+	DoctorCmd.Flags().BoolVar(&kafka, "kafka", kafka, "If specified, check for the strimzi kafka operator")
+	DoctorCmd.Flags().BoolVar(&objectStore, "object-store", objectStore, "If specified, check for the minio object store")
+}
@@ -0,0 +1,53 @@
+package expose
+
+import (
+	"fmt"
+
+	"github.com/BeamStackProj/beamstack-cli/src/objects"
+	"github.com/BeamStackProj/beamstack-cli/src/utils"
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/kubernetes"
+)
+
+var (
+	exposeDashboardLongDesc = utils.LongDesc(`
+		Expose the Grafana Dashboard through an ingress or a load balancer service.
+		`)
+)
+
+// DashboardCmd represents the expose dashboard command
+var DashboardCmd = &cobra.Command{
+	Use:   "dashboard",
+	Short: "expose the grafana dashboard",
+	Long:  exposeDashboardLongDesc,
+	Args: func(cmd *cobra.Command, args []string) error {
+		return checkExposureFlags()
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		profile, err := utils.ValidateCluster()
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		if profile.Monitoring == nil {
+			fmt.Println("Monitoring not enabled on this cluster")
+			return
+		}
+
+		clientset, err := kubernetes.NewForConfig(utils.GetKubeConfig())
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		svc, err := objects.FindGrafanaService(clientset, profile)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		if err := exposeService(profile, "dashboard", svc); err != nil {
+			fmt.Println(err)
+		}
+	},
+}
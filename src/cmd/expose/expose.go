@@ -0,0 +1,234 @@
+/*
+Copyright © 2024 MavenCode <opensource-dev@mavencode.com>
+*/
+package expose
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/BeamStackProj/beamstack-cli/src/objects"
+	"github.com/BeamStackProj/beamstack-cli/src/types"
+	"github.com/BeamStackProj/beamstack-cli/src/utils"
+	"github.com/spf13/cobra"
+	v1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	exposureIngress      = "ingress"
+	exposureLoadBalancer = "loadbalancer"
+)
+
+var (
+	exposureType string = exposureIngress
+	host         string = ""
+	tlsSecret    string = ""
+	ingressClass string = ""
+)
+
+// ExposeCmd represents the expose command
+var ExposeCmd = &cobra.Command{
+	Use:   "expose",
+	Short: "expose resource ui through an ingress or load balancer",
+	Long:  `expose resource ui through an ingress or load balancer so it stays reachable without a port-forward`,
+}
+
+func init() {
+	ExposeCmd.AddCommand(FlinkClusterCmd)
+	ExposeCmd.AddCommand(DashboardCmd)
+
+	ExposeCmd.PersistentFlags().StringVar(&exposureType, "type", exposureType, "how the ui is exposed. One of ingress or loadbalancer")
+	ExposeCmd.PersistentFlags().StringVar(&host, "host", host, "host name of the ingress. Ignored for load balancers")
+	ExposeCmd.PersistentFlags().StringVar(&tlsSecret, "tls-secret", tlsSecret, "secret holding the TLS certificate of the ingress host. Ignored for load balancers")
+	ExposeCmd.PersistentFlags().StringVar(&ingressClass, "ingress-class", ingressClass, "ingress class of the ingress. The cluster default class is used if not provided")
+}
+
+// checkExposureFlags refuses flag combinations the ingress cannot be built from.
+func checkExposureFlags() error {
+	if exposureType == exposureIngress && tlsSecret != "" && host == "" {
+		return fmt.Errorf("--tls-secret requires --host, the TLS certificate is served for the ingress host")
+	}
+	return nil
+}
+
+// exposeService creates an ingress or a load balancer service in front of
+// the service and records the exposure in the profile.
+func exposeService(profile types.Profiles, target string, svc *v1.Service) error {
+	for _, exposure := range profile.Exposures {
+		if exposure.Target == target {
+			return fmt.Errorf("%s is already exposed through %s %s/%s", target, exposure.Type, exposure.Namespace, exposure.Resource)
+		}
+	}
+	if len(svc.Spec.Ports) == 0 {
+		return fmt.Errorf("service %s/%s has no ports", svc.Namespace, svc.Name)
+	}
+
+	clientset, err := kubernetes.NewForConfig(utils.GetKubeConfig())
+	if err != nil {
+		return err
+	}
+
+	exposure := types.Exposure{
+		Target:    target,
+		Type:      exposureType,
+		Namespace: svc.Namespace,
+	}
+	labels := map[string]string{"app.kubernetes.io/managed-by": "beamstack"}
+	var url string
+
+	switch exposureType {
+	case exposureIngress:
+		exposure.Resource = fmt.Sprintf("%s-ingress", svc.Name)
+		exposure.Host = host
+		exposure.TLSSecret = tlsSecret
+
+		pathType := networkingv1.PathTypePrefix
+		ingress := networkingv1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      exposure.Resource,
+				Namespace: svc.Namespace,
+				Labels:    labels,
+			},
+			Spec: networkingv1.IngressSpec{
+				Rules: []networkingv1.IngressRule{
+					{
+						Host: host,
+						IngressRuleValue: networkingv1.IngressRuleValue{
+							HTTP: &networkingv1.HTTPIngressRuleValue{
+								Paths: []networkingv1.HTTPIngressPath{
+									{
+										Path:     "/",
+										PathType: &pathType,
+										Backend: networkingv1.IngressBackend{
+											Service: &networkingv1.IngressServiceBackend{
+												Name: svc.Name,
+												Port: networkingv1.ServiceBackendPort{Number: svc.Spec.Ports[0].Port},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+		if ingressClass != "" {
+			ingress.Spec.IngressClassName = &ingressClass
+		}
+		if tlsSecret != "" {
+			ingress.Spec.TLS = []networkingv1.IngressTLS{
+				{
+					Hosts:      []string{host},
+					SecretName: tlsSecret,
+				},
+			}
+		}
+
+		if _, err := objects.CreateIngress(clientset, ingress); err != nil {
+			return err
+		}
+
+		scheme := "http"
+		if tlsSecret != "" {
+			scheme = "https"
+		}
+		url = fmt.Sprintf("%s://%s", scheme, host)
+		if host == "" {
+			url = fmt.Sprintf("%s://<ingress address>", scheme)
+		}
+
+	case exposureLoadBalancer:
+		exposure.Resource = fmt.Sprintf("%s-lb", svc.Name)
+
+		ports := []v1.ServicePort{}
+		for _, port := range svc.Spec.Ports {
+			ports = append(ports, v1.ServicePort{
+				Name:       port.Name,
+				Port:       port.Port,
+				TargetPort: port.TargetPort,
+				Protocol:   port.Protocol,
+			})
+		}
+		_, err := objects.CreateService(clientset, v1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      exposure.Resource,
+				Namespace: svc.Namespace,
+				Labels:    labels,
+			},
+			Spec: v1.ServiceSpec{
+				Type:     v1.ServiceTypeLoadBalancer,
+				Selector: svc.Spec.Selector,
+				Ports:    ports,
+			},
+		})
+		if err != nil {
+			return err
+		}
+
+		address, err := waitForLoadBalancer(clientset, svc.Namespace, exposure.Resource)
+		if err != nil {
+			fmt.Println(err)
+			address = "<pending>"
+		}
+		url = fmt.Sprintf("http://%s:%d", address, svc.Spec.Ports[0].Port)
+
+	default:
+		return fmt.Errorf("unsupported exposure type %s. use ingress or loadbalancer", exposureType)
+	}
+
+	profile.Exposures = append(profile.Exposures, exposure)
+	if err := utils.SaveProfile(&profile); err != nil {
+		return err
+	}
+
+	fmt.Printf("%s exposed through %s %s/%s\n", target, exposure.Type, exposure.Namespace, exposure.Resource)
+	fmt.Printf("url: %s\n", url)
+	return nil
+}
+
+func waitForLoadBalancer(clientset *kubernetes.Clientset, namespace string, name string) (string, error) {
+	var address string
+
+	fmt.Println("waiting for the load balancer address")
+	err := wait.PollUntilContextTimeout(context.Background(), 3*time.Second, 2*time.Minute, true, func(ctx context.Context) (bool, error) {
+		svc, err := clientset.CoreV1().Services(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		for _, ingress := range svc.Status.LoadBalancer.Ingress {
+			if ingress.Hostname != "" {
+				address = ingress.Hostname
+				return true, nil
+			}
+			if ingress.IP != "" {
+				address = ingress.IP
+				return true, nil
+			}
+		}
+		return false, nil
+	})
+	if wait.Interrupted(err) {
+		return "", fmt.Errorf("load balancer %s/%s has no address yet", namespace, name)
+	}
+	return address, err
+}
+
+func getService(namespace string, name string) (*v1.Service, error) {
+	clientset, err := kubernetes.NewForConfig(utils.GetKubeConfig())
+	if err != nil {
+		return nil, err
+	}
+
+	svc, err := clientset.CoreV1().Services(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return nil, fmt.Errorf("could not find service/%s/%s", namespace, name)
+	}
+	return svc, err
+}
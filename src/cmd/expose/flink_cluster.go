@@ -0,0 +1,77 @@
+package expose
+
+import (
+	"fmt"
+
+	"github.com/BeamStackProj/beamstack-cli/src/objects"
+	"github.com/BeamStackProj/beamstack-cli/src/types"
+	"github.com/BeamStackProj/beamstack-cli/src/utils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	exposeFlinkClusterLongDesc = utils.LongDesc(`
+		Expose the UI of a flink cluster through an ingress or a load balancer service.
+
+		The flink REST API behind the UI has no authentication. Anyone who can
+		reach the ingress or load balancer can submit and cancel jobs, so restrict
+		access to it, for example with a private load balancer or an ingress
+		with authentication.
+		`)
+
+	exposeFlinkClusterExample = utils.Examples(`
+		# Expose a flink cluster through an ingress with TLS
+		beamstack expose flink my-cluster --host flink.example.com --tls-secret flink-tls
+
+		# Expose a flink cluster through a load balancer
+		beamstack expose flink my-cluster --type loadbalancer
+		`)
+)
+
+// FlinkClusterCmd represents the expose flink command
+var FlinkClusterCmd = &cobra.Command{
+	Use:     "flink [NAME]",
+	Short:   "expose the ui of a flink cluster",
+	Long:    exposeFlinkClusterLongDesc,
+	Example: exposeFlinkClusterExample,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			return fmt.Errorf("command requires exactly one argument: Flink Cluster Name. Provided %d arguments", len(args))
+		}
+		return checkExposureFlags()
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		profile, err := utils.ValidateCluster()
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		if profile.Operators.Flink == nil {
+			fmt.Println("Flink Operator not initialized on this cluster")
+			return
+		}
+
+		namespace := utils.WorkloadNamespace(profile.Namespace(types.ComponentFlink))
+		if _, err := objects.GetFlinkCluster(profile, args[0], namespace); err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		svc, err := getService(namespace, fmt.Sprintf("%s-rest", args[0]))
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		fmt.Println("warning: the flink REST API has no authentication. anyone who can reach the exposed ui can submit and cancel jobs")
+		if err := exposeService(profile, flinkTarget(namespace, args[0]), svc); err != nil {
+			fmt.Println(err)
+		}
+	},
+}
+
+// flinkTarget returns the exposure target of a flink cluster. Clusters are
+// keyed by namespace, as clusters of different namespaces can share a name.
+func flinkTarget(namespace string, name string) string {
+	return fmt.Sprintf("flink/%s/%s", namespace, name)
+}
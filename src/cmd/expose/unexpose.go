@@ -0,0 +1,91 @@
+package expose
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/BeamStackProj/beamstack-cli/src/types"
+	"github.com/BeamStackProj/beamstack-cli/src/utils"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+var (
+	unexposeLongDesc = utils.LongDesc(`
+		Remove the ingress or load balancer service created by 'beamstack expose'.
+		`)
+
+	unexposeExample = utils.Examples(`
+		# Remove the exposure of a flink cluster
+		beamstack unexpose flink my-cluster
+
+		# Remove the exposure of the grafana dashboard
+		beamstack unexpose dashboard
+		`)
+)
+
+// UnexposeCmd represents the unexpose command
+var UnexposeCmd = &cobra.Command{
+	Use:     "unexpose [flink NAME | dashboard]",
+	Short:   "remove the exposure of a resource ui",
+	Long:    unexposeLongDesc,
+	Example: unexposeExample,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 2 && args[0] == "flink" || len(args) == 1 && args[0] == "dashboard" {
+			return nil
+		}
+		return fmt.Errorf("unexpose requires either 'flink NAME' or 'dashboard'")
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		profile, err := utils.ValidateCluster()
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		target := args[0]
+		if len(args) == 2 {
+			target = flinkTarget(utils.WorkloadNamespace(profile.Namespace(types.ComponentFlink)), args[1])
+		}
+
+		index := -1
+		for i, exposure := range profile.Exposures {
+			if exposure.Target == target {
+				index = i
+				break
+			}
+		}
+		if index == -1 {
+			fmt.Printf("%s is not exposed\n", target)
+			return
+		}
+		exposure := profile.Exposures[index]
+
+		clientset, err := kubernetes.NewForConfig(utils.GetKubeConfig())
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		switch exposure.Type {
+		case exposureIngress:
+			err = clientset.NetworkingV1().Ingresses(exposure.Namespace).Delete(context.TODO(), exposure.Resource, metav1.DeleteOptions{})
+		case exposureLoadBalancer:
+			err = clientset.CoreV1().Services(exposure.Namespace).Delete(context.TODO(), exposure.Resource, metav1.DeleteOptions{})
+		}
+		if err != nil && !errors.IsNotFound(err) {
+			fmt.Println(err)
+			return
+		}
+
+		profile.Exposures = append(profile.Exposures[:index], profile.Exposures[index+1:]...)
+		if err := utils.SaveProfile(&profile); err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		fmt.Printf("%s %s/%s removed\n", exposure.Type, exposure.Namespace, exposure.Resource)
+	},
+}
@@ -0,0 +1,41 @@
+package flink
+
+import (
+	"fmt"
+
+	"github.com/BeamStackProj/beamstack-cli/src/utils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	cancelLongDesc = utils.LongDesc(`
+		Cancel a job running on a flink cluster.
+		`)
+)
+
+// CancelCmd represents the flink cancel command
+var CancelCmd = &cobra.Command{
+	Use:   "cancel [CLUSTER] [JOB ID]",
+	Short: "cancel a flink job",
+	Long:  cancelLongDesc,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 2 {
+			return fmt.Errorf("cancel command requires exactly two arguments: cluster name and job id. Provided %d arguments", len(args))
+		}
+		return nil
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		client, stop, err := connect(args[0])
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		defer stop()
+
+		if err := client.Cancel(args[1]); err != nil {
+			fmt.Println(err)
+			return
+		}
+		fmt.Printf("Job %s cancelled\n", args[1])
+	},
+}
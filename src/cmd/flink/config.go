@@ -0,0 +1,47 @@
+package flink
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/BeamStackProj/beamstack-cli/src/utils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	configLongDesc = utils.LongDesc(`
+		Print the effective configuration of the job manager of a flink cluster.
+		`)
+)
+
+// ConfigCmd represents the flink config command
+var ConfigCmd = &cobra.Command{
+	Use:   "config [CLUSTER]",
+	Short: "print the configuration of a flink cluster",
+	Long:  configLongDesc,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			return fmt.Errorf("config command requires exactly one argument: cluster name. Provided %d arguments", len(args))
+		}
+		return nil
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		client, stop, err := connect(args[0])
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		defer stop()
+
+		config, err := client.Config()
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		sort.Slice(config, func(i, j int) bool { return config[i].Key < config[j].Key })
+		for _, entry := range config {
+			fmt.Printf("%s: %s\n", entry.Key, entry.Value)
+		}
+	},
+}
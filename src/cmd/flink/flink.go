@@ -0,0 +1,121 @@
+/*
+Copyright © 2024 MavenCode <opensource-dev@mavencode.com>
+*/
+package flink
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"time"
+
+	flink_handler "github.com/BeamStackProj/beamstack-cli/src/handlers/flink"
+	"github.com/BeamStackProj/beamstack-cli/src/objects"
+	"github.com/BeamStackProj/beamstack-cli/src/types"
+	"github.com/BeamStackProj/beamstack-cli/src/utils"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/kubernetes"
+)
+
+// FlinkCmd represents the flink command
+var FlinkCmd = &cobra.Command{
+	Use:   "flink",
+	Short: "manage jobs running on flink clusters",
+	Long:  `manage jobs running on flink clusters through the flink REST API`,
+}
+
+func init() {
+	FlinkCmd.AddCommand(JobsCmd)
+	FlinkCmd.AddCommand(CancelCmd)
+	FlinkCmd.AddCommand(SavepointsCmd)
+	FlinkCmd.AddCommand(ConfigCmd)
+}
+
+// connect port-forwards the rest service of a flink cluster to a free local
+// port and returns a client for it. The returned function stops the port-forward.
+func connect(name string) (*flink_handler.Client, func(), error) {
+	profile, err := utils.ValidateCluster()
+	if err != nil {
+		return nil, nil, err
+	}
+	if profile.Operators.Flink == nil {
+		return nil, nil, fmt.Errorf("Flink Operator not initialized on this cluster")
+	}
+
+	clientset, err := kubernetes.NewForConfig(utils.GetKubeConfig())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	namespace := utils.WorkloadNamespace(profile.Namespace(types.ComponentFlink))
+	if _, err := objects.GetFlinkCluster(profile, name, namespace); err != nil {
+		return nil, nil, err
+	}
+	svcName := fmt.Sprintf("%s-rest", name)
+	svc, err := clientset.CoreV1().Services(namespace).Get(context.TODO(), svcName, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return nil, nil, fmt.Errorf("could not find service/%s/%s", namespace, svcName)
+	} else if err != nil {
+		return nil, nil, err
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not find a free local port: %s", err)
+	}
+	localPort := listener.Addr().(*net.TCPAddr).Port
+	listener.Close()
+
+	stopCh := make(chan struct{}, 1)
+	readyCh := make(chan struct{})
+	errCh := make(chan error, 1)
+
+	go func() {
+		errCh <- utils.PortForwardSvc(
+			clientset,
+			types.PortForwardASVCRequest{
+				PortForward: types.PortForward{
+					PodPort:   8081,
+					LocalPort: uint16(localPort),
+					Streams: genericclioptions.IOStreams{
+						Out:    io.Discard,
+						ErrOut: io.Discard,
+					},
+					StopCh:  stopCh,
+					ReadyCh: readyCh,
+				},
+				Service: *svc,
+			},
+		)
+	}()
+
+	select {
+	case <-readyCh:
+	case err := <-errCh:
+		return nil, nil, fmt.Errorf("could not port-forward to service/%s/%s: %v", namespace, svcName, err)
+	case <-time.After(30 * time.Second):
+		close(stopCh)
+		return nil, nil, fmt.Errorf("timed out port-forwarding to service/%s/%s", namespace, svcName)
+	}
+
+	client := flink_handler.NewClient(fmt.Sprintf("http://127.0.0.1:%d", localPort))
+	return client, func() { close(stopCh) }, nil
+}
+
+func formatDuration(milliseconds int64) string {
+	if milliseconds < 0 {
+		return "-"
+	}
+	return (time.Duration(milliseconds) * time.Millisecond).Round(time.Second).String()
+}
+
+func formatTimestamp(milliseconds int64) string {
+	if milliseconds <= 0 {
+		return "-"
+	}
+	return time.UnixMilli(milliseconds).Format(time.DateTime)
+}
@@ -0,0 +1,122 @@
+package flink
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/BeamStackProj/beamstack-cli/src/utils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	jobsLongDesc = utils.LongDesc(`
+		List the jobs of a flink cluster with their state and duration. When a job id is
+		provided, the metrics of every vertex and the exception history of the job are shown.
+		`)
+
+	jobsExample = utils.Examples(`
+		# List the jobs of a cluster
+		beamstack flink jobs my-cluster
+
+		# Show vertex metrics and exceptions of a job
+		beamstack flink jobs my-cluster 4f1c6a0b3e2d4c5f8a9b0c1d2e3f4a5b
+		`)
+)
+
+// JobsCmd represents the flink jobs command
+var JobsCmd = &cobra.Command{
+	Use:     "jobs [CLUSTER] [JOB ID]",
+	Short:   "list the jobs of a flink cluster",
+	Long:    jobsLongDesc,
+	Example: jobsExample,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) < 1 || len(args) > 2 {
+			return fmt.Errorf("jobs command requires the cluster name and optionally a job id. Provided %d arguments", len(args))
+		}
+		return nil
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		client, stop, err := connect(args[0])
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		defer stop()
+
+		if len(args) == 1 {
+			jobs, err := client.Jobs()
+			if err != nil {
+				fmt.Println(err)
+				return
+			}
+
+			fmt.Printf("%-34s %-30s %-12s %-20s %s\n", "JOB ID", "NAME", "STATE", "START", "DURATION")
+			for _, job := range jobs {
+				fmt.Printf("%-34s %-30s %-12s %-20s %s\n", job.ID, job.Name, job.State, formatTimestamp(job.StartTime), formatDuration(job.Duration))
+			}
+			return
+		}
+
+		job, err := client.Job(args[1])
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		fmt.Printf("%-12s %s\n", "Job ID:", job.ID)
+		fmt.Printf("%-12s %s\n", "Name:", job.Name)
+		fmt.Printf("%-12s %s\n", "State:", job.State)
+		fmt.Printf("%-12s %s\n", "Duration:", formatDuration(job.Duration))
+
+		fmt.Println("Vertices:")
+		fmt.Printf("  %-40s %-12s %-11s %-14s %-14s %-12s %s\n", "NAME", "STATUS", "PARALLELISM", "RECORDS IN", "RECORDS OUT", "BYTES IN", "BYTES OUT")
+		for _, vertex := range job.Vertices {
+			name := vertex.Name
+			if len(name) > 40 {
+				name = name[:37] + "..."
+			}
+			fmt.Printf("  %-40s %-12s %-11d %-14d %-14d %-12d %d\n",
+				name,
+				vertex.Status,
+				vertex.Parallelism,
+				vertex.Metrics.ReadRecords,
+				vertex.Metrics.WriteRecords,
+				vertex.Metrics.ReadBytes,
+				vertex.Metrics.WriteBytes,
+			)
+		}
+
+		exceptions, err := client.Exceptions(args[1])
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		if len(exceptions.History.Entries) == 0 {
+			fmt.Println("Exceptions: none")
+			return
+		}
+		fmt.Println("Exceptions:")
+		for _, exception := range exceptions.History.Entries {
+			task := ""
+			if exception.TaskName != nil {
+				task = fmt.Sprintf(" in %s", *exception.TaskName)
+			}
+			fmt.Printf("  %s %s%s\n", formatTimestamp(exception.Timestamp), exception.Name, task)
+			if verbose {
+				for _, line := range strings.Split(exception.Stacktrace, "\n") {
+					fmt.Printf("    %s\n", line)
+				}
+			}
+		}
+		if exceptions.History.Truncated {
+			fmt.Println("  older exceptions were truncated")
+		}
+	},
+}
+
+var verbose bool = false
+
+func init() {
+	JobsCmd.Flags().BoolVarP(&verbose, "verbose", "v", verbose, "print the stack traces of job exceptions")
+}
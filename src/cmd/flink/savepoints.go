@@ -0,0 +1,89 @@
+package flink
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/BeamStackProj/beamstack-cli/src/types"
+	"github.com/BeamStackProj/beamstack-cli/src/utils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	savepointsLongDesc = utils.LongDesc(`
+		Trigger a savepoint of a job running on a flink cluster and wait for it to complete.
+		The savepoint is written to the savepoint directory of the cluster unless --dir is provided.
+		`)
+
+	savepointsExample = utils.Examples(`
+		# Trigger a savepoint
+		beamstack flink savepoints my-cluster 4f1c6a0b3e2d4c5f8a9b0c1d2e3f4a5b
+
+		# Trigger a savepoint to a custom directory and stop the job
+		beamstack flink savepoints my-cluster 4f1c6a0b3e2d4c5f8a9b0c1d2e3f4a5b --dir s3://bucket/savepoints --cancel
+		`)
+
+	savepointDir     string        = ""
+	cancelJob        bool          = false
+	savepointTimeout time.Duration = 10 * time.Minute
+)
+
+// SavepointsCmd represents the flink savepoints command
+var SavepointsCmd = &cobra.Command{
+	Use:     "savepoints [CLUSTER] [JOB ID]",
+	Short:   "trigger a savepoint of a flink job",
+	Long:    savepointsLongDesc,
+	Example: savepointsExample,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 2 {
+			return fmt.Errorf("savepoints command requires exactly two arguments: cluster name and job id. Provided %d arguments", len(args))
+		}
+		return nil
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		client, stop, err := connect(args[0])
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		defer stop()
+
+		triggerID, err := client.TriggerSavepoint(args[1], types.FlinkSavepointRequest{
+			TargetDirectory: savepointDir,
+			CancelJob:       cancelJob,
+		})
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		fmt.Printf("savepoint triggered: %s\n", triggerID)
+
+		deadline := time.Now().Add(savepointTimeout)
+		for time.Now().Before(deadline) {
+			status, err := client.SavepointStatus(args[1], triggerID)
+			if err != nil {
+				fmt.Println(err)
+				return
+			}
+
+			if status.Status.ID == "COMPLETED" {
+				if status.Operation != nil && status.Operation.FailureCause != nil {
+					fmt.Printf("savepoint failed: %s\n", status.Operation.FailureCause.Class)
+					return
+				}
+				if status.Operation != nil {
+					fmt.Printf("savepoint completed: %s\n", status.Operation.Location)
+				}
+				return
+			}
+			time.Sleep(2 * time.Second)
+		}
+		fmt.Printf("savepoint %s still in progress after %s\n", triggerID, savepointTimeout)
+	},
+}
+
+func init() {
+	SavepointsCmd.Flags().StringVar(&savepointDir, "dir", savepointDir, "directory the savepoint is written to. Defaults to the savepoint directory of the cluster")
+	SavepointsCmd.Flags().BoolVar(&cancelJob, "cancel", cancelJob, "cancel the job once the savepoint completes")
+	SavepointsCmd.Flags().DurationVar(&savepointTimeout, "timeout", savepointTimeout, "how long to wait for the savepoint to complete")
+}
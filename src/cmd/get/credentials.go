@@ -0,0 +1,91 @@
+package get
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/BeamStackProj/beamstack-cli/src/objects"
+	"github.com/BeamStackProj/beamstack-cli/src/types"
+	"github.com/BeamStackProj/beamstack-cli/src/utils"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+var (
+	esCredentialsLongDesc = utils.LongDesc(`
+		Display the credentials of the elastic user of an elasticsearch cluster.
+		`)
+
+	esCredentialsExample = utils.Examples(`
+		# Display the credentials of an elasticsearch cluster
+		beamstack get credentials elasticsearch my-es --namespace default
+		`)
+)
+
+// CredentialsCmd represents the get credentials command
+var CredentialsCmd = &cobra.Command{
+	Use:   "credentials",
+	Short: "display resource credentials",
+	Long:  `display credentials of resources created by beamstack`,
+}
+
+// ElasticSearchCredentialsCmd represents the get credentials elasticsearch command
+var ElasticSearchCredentialsCmd = &cobra.Command{
+	Use:     "elasticsearch [NAME]",
+	Short:   "display elasticsearch credentials",
+	Long:    esCredentialsLongDesc,
+	Example: esCredentialsExample,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			return fmt.Errorf("elasticsearch command requires exactly one argument: cluster Name. Provided %d arguments", len(args))
+		}
+		return nil
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		profile, err := utils.ValidateCluster()
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		clientset, err := kubernetes.NewForConfig(utils.GetKubeConfig())
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		esNamespace := utils.WorkloadNamespace("default")
+		if _, err := objects.GetElasticsearch(profile, args[0], esNamespace); errors.IsNotFound(err) {
+			fmt.Printf("could not find elasticsearch cluster %s/%s\n", esNamespace, args[0])
+			return
+		} else if err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		secretName := types.EsCredentialsSecret(args[0])
+		secret, err := clientset.CoreV1().Secrets(esNamespace).Get(context.TODO(), secretName, metav1.GetOptions{})
+		if errors.IsNotFound(err) {
+			fmt.Printf("could not find secret/%s/%s\n", esNamespace, secretName)
+			return
+		} else if err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		password, ok := secret.Data["elastic"]
+		if !ok {
+			fmt.Printf("secret/%s/%s has no credentials for the elastic user\n", esNamespace, secretName)
+			return
+		}
+
+		fmt.Printf("Username:\telastic\n")
+		fmt.Printf("Password:\t%s\n", password)
+	},
+}
+
+func init() {
+	CredentialsCmd.AddCommand(ElasticSearchCredentialsCmd)
+}
@@ -0,0 +1,128 @@
+package get
+
+import (
+	"fmt"
+
+	"github.com/BeamStackProj/beamstack-cli/src/objects"
+	"github.com/BeamStackProj/beamstack-cli/src/types"
+	"github.com/BeamStackProj/beamstack-cli/src/utils"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+var (
+	getFlinkLongDesc = utils.LongDesc(`
+		Display the flink clusters created by beamstack. When a cluster name is provided,
+		the detailed settings of that cluster are shown.
+		`)
+
+	getFlinkExample = utils.Examples(`
+		# List all flink clusters
+		beamstack get flink
+
+		# Show the settings of a single flink cluster
+		beamstack get flink my-cluster
+		`)
+)
+
+// FlinkClusterCmd represents the get flink command
+var FlinkClusterCmd = &cobra.Command{
+	Use:     "flink [NAME]",
+	Short:   "display flink clusters",
+	Long:    getFlinkLongDesc,
+	Example: getFlinkExample,
+	Args:    cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		profile, err := utils.ValidateCluster()
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		if profile.Operators.Flink == nil {
+			fmt.Println("Flink Operator not initialized on this cluster")
+			return
+		}
+		namespace := utils.WorkloadNamespace(profile.Namespace(types.ComponentFlink))
+
+		if len(args) == 1 {
+			deployment, err := objects.GetFlinkCluster(profile, args[0], namespace)
+			if err != nil {
+				fmt.Println(err)
+				return
+			}
+			describeFlinkDeployment(deployment)
+			return
+		}
+
+		items, err := objects.ListDynamicResources(types.FlinkDeploymentGVR, namespace)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		fmt.Printf("%-30s %-12s %-13s %-5s %s\n", "NAME", "JOBMANAGERS", "TASKMANAGERS", "HA", "STATUS")
+		for _, item := range items {
+			var deployment types.FlinkDeployment
+			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(item.Object, &deployment); err != nil {
+				fmt.Printf("could not read flink cluster %s: %s\n", item.GetName(), err)
+				continue
+			}
+			if !profile.Owns(deployment.Labels) {
+				continue
+			}
+			fmt.Printf("%-30s %-12d %-13d %-5t %s\n",
+				deployment.Name,
+				jobManagerReplicas(deployment.Spec),
+				deployment.Spec.TaskManager.Replicas,
+				deployment.Spec.HighAvailability(),
+				deployment.Status.JobManagerDeploymentStatus,
+			)
+		}
+	},
+}
+
+func describeFlinkDeployment(deployment types.FlinkDeployment) {
+	spec := deployment.Spec
+
+	fmt.Printf("%-24s %s\n", "Name:", deployment.Name)
+	fmt.Printf("%-24s %s\n", "Namespace:", deployment.Namespace)
+	fmt.Printf("%-24s %s\n", "Flink Version:", spec.FlinkVersion)
+	fmt.Printf("%-24s %s\n", "Harness:", deployment.Harness())
+	fmt.Printf("%-24s %s\n", "Status:", deployment.Status.JobManagerDeploymentStatus)
+	fmt.Printf("%-24s %s\n", "Lifecycle:", deployment.Status.LifecycleState)
+	fmt.Printf("%-24s %d\n", "Job Managers:", jobManagerReplicas(spec))
+	fmt.Printf("%-24s %d\n", "Task Managers:", spec.TaskManager.Replicas)
+	fmt.Printf("%-24s %s\n", "Task Slots:", spec.FlinkConfiguration["taskmanager.numberOfTaskSlots"])
+
+	fmt.Println("High Availability:")
+	fmt.Printf("  %-22s %t\n", "Enabled:", spec.HighAvailability())
+	if spec.HighAvailability() {
+		fmt.Printf("  %-22s %s\n", "Mode:", spec.FlinkConfiguration["high-availability"])
+		fmt.Printf("  %-22s %s\n", "Storage Dir:", spec.FlinkConfiguration["high-availability.storageDir"])
+	}
+
+	fmt.Println("State Backend:")
+	fmt.Printf("  %-22s %s\n", "Type:", valueOrNone(spec.FlinkConfiguration["state.backend"]))
+	fmt.Printf("  %-22s %s\n", "Incremental:", valueOrNone(spec.FlinkConfiguration["state.backend.incremental"]))
+	fmt.Printf("  %-22s %s\n", "Checkpoints Dir:", valueOrNone(spec.FlinkConfiguration["state.checkpoints.dir"]))
+	fmt.Printf("  %-22s %s\n", "Savepoints Dir:", valueOrNone(spec.FlinkConfiguration["state.savepoints.dir"]))
+	fmt.Printf("  %-22s %s\n", "Checkpoints Retained:", valueOrNone(spec.FlinkConfiguration["state.checkpoints.num-retained"]))
+	fmt.Printf("  %-22s %s\n", "Retention:", valueOrNone(spec.FlinkConfiguration["execution.checkpointing.externalized-checkpoint-retention"]))
+	if endpoint, ok := spec.FlinkConfiguration["s3.endpoint"]; ok {
+		fmt.Printf("  %-22s %s\n", "S3 Endpoint:", endpoint)
+	}
+}
+
+func valueOrNone(value string) string {
+	if value == "" {
+		return "<none>"
+	}
+	return value
+}
+
+func jobManagerReplicas(spec types.FlinkDeploymentSpec) uint8 {
+	if spec.JobManager.Replicas == 0 {
+		return 1
+	}
+	return spec.JobManager.Replicas
+}
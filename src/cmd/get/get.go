@@ -0,0 +1,20 @@
+/*
+Copyright © 2024 MavenCode <opensource-dev@mavencode.com>
+*/
+package get
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// GetCmd represents the get command
+var GetCmd = &cobra.Command{
+	Use:   "get",
+	Short: "display resources",
+	Long:  `display resources created by beamstack`,
+}
+
+func init() {
+	GetCmd.AddCommand(FlinkClusterCmd)
+	GetCmd.AddCommand(CredentialsCmd)
+}
@@ -5,10 +5,11 @@ package info
 
 import (
 	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/BeamStackProj/beamstack-cli/src/utils"
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
 )
 
 // Long Description and Example initizalization
@@ -29,10 +30,19 @@ var InfoCmd = &cobra.Command{
 			fmt.Println("could not retrieve current context")
 		}
 		fmt.Printf("current kube context: %s\n", ctx)
-		contextsStringMap := viper.GetStringMapString("contexts")
+		profiles := utils.EnvironmentProfiles(ctx)
+
+		environments := []string{}
+		for environment := range profiles {
+			environments = append(environments, environment)
+		}
+		sort.Strings(environments)
+		if len(environments) > 1 {
+			fmt.Printf("environments: %s\n", strings.Join(environments, ", "))
+		}
 
 		var profileName string
-		if p, ok := contextsStringMap[ctx]; ok {
+		if p, ok := profiles[utils.Environment]; ok {
 			fmt.Printf("Current profile: %s\n", p)
 			profileName = p
 		}
@@ -0,0 +1,81 @@
+package initialize
+
+import (
+	"fmt"
+
+	"golang.org/x/term"
+
+	doctor_handler "github.com/BeamStackProj/beamstack-cli/src/handlers/doctor"
+	"github.com/BeamStackProj/beamstack-cli/src/objects"
+	"github.com/BeamStackProj/beamstack-cli/src/types"
+	"github.com/BeamStackProj/beamstack-cli/src/utils"
+	"k8s.io/client-go/kubernetes"
+)
+
+// adoptInstalls offers to adopt the existing installs of the components of
+// the steps instead of installing them over. Adopted installs are recorded as
+// external packages and their steps are completed. Installs another
+// environment of the cluster adopted, and every install with --adopt, are
+// adopted without asking.
+func adoptInstalls(clientset *kubernetes.Clientset, profile *types.Profiles, steps []initStep, profiles map[string]string) error {
+	for _, step := range steps {
+		if recorded := profile.Step(step.Name); recorded != nil && recorded.Status == types.StepCompleted {
+			continue
+		}
+
+		install, found, err := doctor_handler.DetectInstall(clientset, step.Name, config.Namespace(step.Name))
+		if err != nil {
+			return err
+		} else if !found || install.Namespace == config.Namespace(step.Name) {
+			// an install in the namespace of init is reused as is
+			continue
+		}
+		pkg := doctor_handler.ExternalPackage(install)
+
+		adopted := false
+		for _, name := range profiles {
+			other, err := utils.GetProfile(name)
+			if err != nil {
+				continue
+			}
+			for _, installed := range other.Packages {
+				if installed.Name == pkg.Name && installed.Type == "external" {
+					adopted = true
+				}
+			}
+		}
+
+		if !adopted && !adopt {
+			fmt.Printf("found an existing %s install: deployment %s/%s", step.Name, install.Namespace, install.Deployment)
+			if install.Version != "" {
+				fmt.Printf(", version %s", install.Version)
+			}
+			fmt.Println()
+			if !term.IsTerminal(fd) {
+				return fmt.Errorf("stdin is not a terminal. pass --adopt to adopt the existing %s install", step.Name)
+			}
+			fmt.Print("Do you want to adopt it instead of installing beamstack's own? (y/n) ")
+
+			var userInput string
+			if _, err := fmt.Scanln(&userInput); err != nil {
+				return fmt.Errorf("error reading input: %v", err)
+			}
+			if userInput != "Y" && userInput != "y" {
+				fmt.Printf("installing %s over the existing install\n", step.Name)
+				continue
+			}
+		}
+
+		recordStep(profile, step, pkg)
+		if step.Name == types.ComponentMonitoring {
+			profile.Monitoring.Namespace = install.Namespace
+			if svc, err := objects.LookupGrafanaService(clientset, install.Namespace); err == nil {
+				profile.Monitoring.Namespace, profile.Monitoring.GrafanaService = svc.Namespace, svc.Name
+			} else {
+				fmt.Printf("could not locate the grafana service of the existing monitoring stack: %s\n", err)
+			}
+		}
+		fmt.Printf("adopted the existing %s install in namespace %s\n", step.Name, install.Namespace)
+	}
+	return nil
+}
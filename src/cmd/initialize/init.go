@@ -3,15 +3,17 @@ package initialize
 import (
 	"fmt"
 	"os"
+	"strings"
 
 	"golang.org/x/term"
 
-	"github.com/BeamStackProj/beamstack-cli/src/objects"
+	doctor_handler "github.com/BeamStackProj/beamstack-cli/src/handlers/doctor"
 	"github.com/BeamStackProj/beamstack-cli/src/types"
 	"github.com/BeamStackProj/beamstack-cli/src/utils"
 	"github.com/google/uuid"
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/kubernetes"
 )
 
 var (
@@ -20,6 +22,8 @@ var (
 		`)
 )
 
+const grafanaPasswordEnv = "BEAMSTACK_GRAFANA_PASSWORD"
+
 var InitCmd = &cobra.Command{
 	Use:   "init",
 	Short: "Initialize beamstack on cluster",
@@ -38,20 +42,32 @@ var Banner string = `
 `
 
 var (
-	ConfigFile      string = ""
-	Name            string = ""
-	DefaultOperator string = "flink"
-	FlinkVersion    string = "1.8.0"
-	SparkVersion    string = "latest"
-	elasticsearch   bool   = false
-	monitoring      bool   = false
-	Flink           bool   = false
-	Spark           bool   = false
-	operators       types.Operator
-	force           bool = false
-	totalOps        int  = 8
-	currentOp       int  = 1
-	fd              int  = int(os.Stdin.Fd())
+	ConfigFile          string = ""
+	Name                string = ""
+	DefaultOperator     string = "flink"
+	FlinkVersion        string = types.FlinkOperatorVersion
+	SparkVersion        string = "latest"
+	elasticsearch       bool   = false
+	monitoring          bool   = false
+	kafka               bool   = false
+	objectStore         bool   = false
+	objectStoreSize     string = "10Gi"
+	Flink               bool   = false
+	Spark               bool   = false
+	config              types.ProfileConfig
+	force               bool   = false
+	resume              bool   = false
+	skipPreflight       bool   = false
+	yes                 bool   = false
+	adopt               bool   = false
+	grafanaUser         string = ""
+	grafanaPasswordFile string = ""
+	bundlePath          string = ""
+	valuesFiles         []string
+	bundle              *utils.Bundle
+	totalOps            int = 1
+	currentOp           int = 1
+	fd                  int = int(os.Stdin.Fd())
 )
 
 func init() {
@@ -64,8 +80,19 @@ func init() {
 	InitCmd.Flags().BoolVarP(&Flink, "flink", "F", Flink, "If specified, flink is installed.")
 	InitCmd.Flags().BoolVarP(&Spark, "spark", "S", Spark, "If specified, Spark is installed.")
 	InitCmd.Flags().BoolVarP(&force, "force", "q", force, "If specified, will automatically reinitialize cluster")
+	InitCmd.Flags().BoolVarP(&yes, "yes", "y", yes, "If specified, answer yes to every prompt")
+	InitCmd.Flags().BoolVar(&adopt, "adopt", adopt, "If specified, adopt existing installs of the components without prompting")
+	InitCmd.Flags().StringVar(&grafanaUser, "grafana-user", grafanaUser, "Grafana admin username. Prompted for if not provided.")
+	InitCmd.Flags().StringVar(&grafanaPasswordFile, "grafana-password-file", grafanaPasswordFile, fmt.Sprintf("File holding the grafana admin password. The %s environment variable is used if not provided, and the password is prompted for otherwise.", grafanaPasswordEnv))
+	InitCmd.Flags().StringArrayVar(&valuesFiles, "values", valuesFiles, "Helm values file of a component, as component=file.yaml. Merged over the default values of the component. Can be repeated.")
+	InitCmd.Flags().StringVar(&bundlePath, "bundle", bundlePath, "Path to a bundle created with 'beamstack bundle create'. Manifests and charts are installed from the bundle instead of being downloaded.")
+	InitCmd.Flags().BoolVar(&skipPreflight, "skip-preflight", skipPreflight, "If specified, install without running the pre-flight checks of 'beamstack doctor'")
+	InitCmd.Flags().BoolVar(&resume, "resume", resume, "If specified, resume a failed init of the current cluster, skipping the steps it completed")
 	InitCmd.Flags().BoolVarP(&monitoring, "monitoring", "m", monitoring, "If specified, install prometheus + grafana stack")
 	InitCmd.Flags().BoolVarP(&elasticsearch, "elasticsearch", "e", elasticsearch, "If specified, install elasticsearch operator")
+	InitCmd.Flags().BoolVar(&kafka, "kafka", kafka, "If specified, install strimzi kafka operator")
+	InitCmd.Flags().BoolVar(&objectStore, "object-store", objectStore, "If specified, install minio as an s3 compatible object store")
+	InitCmd.Flags().StringVar(&objectStoreSize, "object-store-size", objectStoreSize, "Size of the object store volume. Ignored if the object store is not specified for installation.")
 }
 
 func runInit(cmd *cobra.Command, args []string) {
@@ -76,315 +103,374 @@ func runInit(cmd *cobra.Command, args []string) {
 		return
 	}
 
-	if !monitoring {
-		totalOps -= 2
-	}
-
-	if !elasticsearch {
-		totalOps -= 2
+	if errs := validation.IsDNS1123Label(utils.Environment); len(errs) > 0 {
+		fmt.Printf("invalid environment %s: %s\n", utils.Environment, strings.Join(errs, ", "))
+		return
 	}
-	contextsStringMap := viper.GetStringMapString("contexts")
+	profiles := utils.EnvironmentProfiles(currentContext)
 
-	if _, ok := contextsStringMap[currentContext]; ok && !force {
-		fmt.Println("Current cluster already initialized")
-		fmt.Print("Do you want reinitialize? (y/n) ")
+	var Profile types.Profiles
 
-		var userInput string
-		_, err := fmt.Scanln(&userInput)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error reading input: %v\n", err)
+	if resume {
+		profileName, ok := profiles[utils.Environment]
+		if !ok {
+			fmt.Println("Current cluster has no init to resume. please run 'beamstack init'")
 			return
 		}
-
-		switch userInput {
-		case "Y", "y":
-			fmt.Println("ReInitializing...")
-		case "N", "n":
-			fmt.Println("Aborting...")
+		Profile, err = utils.GetProfile(profileName)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
 			return
-		default:
-			fmt.Println("Invalid input. Aborting...")
+		}
+		if len(Profile.Steps) == 0 {
+			fmt.Println("Current profile has no recorded init steps. please run 'beamstack init'")
 			return
 		}
-	}
-
-	var Profile types.Profiles
+		config = recordedConfig(Profile)
+	} else {
+		if _, ok := profiles[utils.Environment]; ok && !force && !yes {
+			if utils.Environment != types.DefaultEnvironment {
+				fmt.Printf("Environment %s of current cluster already initialized\n", utils.Environment)
+			} else {
+				fmt.Println("Current cluster already initialized")
+			}
+			if !term.IsTerminal(fd) {
+				fmt.Println("stdin is not a terminal. pass --yes to reinitialize")
+				return
+			}
+			fmt.Print("Do you want reinitialize? (y/n) ")
 
-	if ConfigFile == "" {
+			var userInput string
+			_, err := fmt.Scanln(&userInput)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error reading input: %v\n", err)
+				return
+			}
 
-		if Name == "" {
-			Name = uuid.NewString()
+			switch userInput {
+			case "Y", "y":
+				fmt.Println("ReInitializing...")
+			case "N", "n":
+				fmt.Println("Aborting...")
+				return
+			default:
+				fmt.Println("Invalid input. Aborting...")
+				return
+			}
 		}
 
-		if !Flink && !Spark {
-			Flink = true
+		config, err = profileConfig(cmd)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			return
+		}
+		if err := applyValuesFiles(&config, valuesFiles); err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			return
 		}
+		config = config.ForEnvironment(utils.Environment)
+		Profile = newProfile(config)
+	}
 
-		if Flink {
-			flinkDefault := false
-			if DefaultOperator == "flink" {
-				flinkDefault = true
-			}
-			operators.Flink = &types.OperatorDetails{
-				Version:   FlinkVersion,
-				IsDefault: flinkDefault,
-			}
+	steps := initSteps()
+	totalOps = len(steps)
+	for _, step := range steps {
+		if Profile.Step(step.Name) == nil {
+			Profile.SetStep(step.Name, types.StepPending, nil)
 		}
+	}
 
-		if Spark {
-			sparkDefault := false
-			if DefaultOperator == "spark" {
-				sparkDefault = true
-			}
-			operators.Spark = &types.OperatorDetails{
-				Version:   SparkVersion,
-				IsDefault: sparkDefault,
+	if bundlePath != "" {
+		bundle, err = utils.OpenBundle(bundlePath)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		defer bundle.Close()
+
+		for _, step := range steps {
+			for _, artifact := range types.ComponentArtifacts(step.Name, config) {
+				if _, ok := bundle.Path(artifact.Source, artifact.Chart); !ok {
+					fmt.Printf("bundle %s does not contain %s for %s\n", bundlePath, artifact.Source, step.Name)
+					return
+				}
 			}
 		}
+	}
 
-		Profile = types.Profiles{
-			Name:       Name,
-			Operators:  operators,
-			Monitoring: nil,
-			Packages:   []types.Package{},
+	if !skipPreflight {
+		components := []types.Component{}
+		for _, step := range steps {
+			if component, ok := config.GetComponent(step.Name); ok {
+				components = append(components, component)
+			}
 		}
 
-	} else {
-		Profile, err = utils.LoadProfileFromConfig(ConfigFile)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "%v\n", err)
+		fmt.Println("running pre-flight checks")
+		report := doctor_handler.Preflight(components)
+		doctor_handler.PrintPreflightReport(report)
+		fmt.Println()
+		if report.Failed() {
+			fmt.Println("pre-flight checks failed. fix the failures above, or pass --skip-preflight to install anyway")
 			return
 		}
+	}
 
+	clientset, err := kubernetes.NewForConfig(utils.GetKubeConfig())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		return
 	}
 
-	if err := viper.WriteConfig(); err != nil {
-		fmt.Printf("Error writing config file: %v\n", err)
+	if err := adoptInstalls(clientset, &Profile, steps, profiles); err != nil {
+		fmt.Println(err)
+		return
 	}
 
-	fmt.Println("installing cert manager crds")
+	if !term.IsTerminal(fd) {
+		if missing := missingInputs(Profile); len(missing) > 0 {
+			fmt.Printf("stdin is not a terminal and init cannot prompt for: %s\n", strings.Join(missing, ", "))
+			return
+		}
+	}
 
-	if err := objects.CreateObject("https://github.com/jetstack/cert-manager/releases/download/v1.8.2/cert-manager.yaml"); err != nil {
-		fmt.Printf("could not install cert manager: \n%s\n", err)
+	// the profile is saved before any step runs so a failed init can be resumed
+	if err := utils.SetEnvironmentProfile(currentContext, utils.Environment, Profile.Name); err != nil {
+		fmt.Printf("Error writing config file: %v\n", err)
+	}
+	if err := utils.SaveProfile(&Profile); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
 		return
 	}
 
-	progChan := make(chan types.ProgCount)
-	go objects.HandleResources("CustomResourceDefinition", "", "Established", progChan)
-	utils.DisplayProgress(progChan, "deploying crds", fmt.Sprintf("%d/%d", currentOp, totalOps))
-	currentOp += 1
-
-	progChan = make(chan types.ProgCount)
-	go objects.HandleResources("Deployment", "cert-manager", "Available", progChan)
-	utils.DisplayProgress(progChan, "creating deployments", fmt.Sprintf("%d/%d", currentOp, totalOps))
-	currentOp += 1
-
-	Profile.Packages = append(Profile.Packages, types.Package{
-		Name:    "cert-manager",
-		Url:     "https://github.com/jetstack/cert-manager/releases/download/v1.8.2/cert-manager.yaml",
-		Type:    "k8s",
-		Version: "1.8.2",
-	})
-
-	if Flink {
-		flinkNamespace := "flink"
-
-		if err := objects.CreateNamespace(flinkNamespace); err != nil {
-			// handler error?...
-			_ = fmt.Sprintf("%s", err)
-		}
-		flinkValues := map[string]interface{}{
-			"defaultConfiguration": map[string]interface{}{
-				"create": true,
-				"append": true,
-				"flink-conf.yaml": `
-              kubernetes.operator.metrics.reporter.prom.factory.class: org.apache.flink.metrics.prometheus.PrometheusReporterFactory
-              kubernetes.operator.metrics.reporter.prom.port: 9999
-              metrics.port: 9999
-            `,
-			},
-		}
-
-		fmt.Println("\ninstalling flink operator")
-		helmPackage := utils.InstallHelmPackage("flink-kubernetes-operator", "", fmt.Sprintf("https://downloads.apache.org/flink/flink-kubernetes-operator-%s/", FlinkVersion), FlinkVersion, flinkNamespace, &flinkValues)
-
-		progChan := make(chan types.ProgCount)
-		go objects.HandleResources("CustomResourceDefinition", "", "Established", progChan)
-		utils.DisplayProgress(progChan, "installing flink", fmt.Sprintf("%d/%d", currentOp, totalOps))
-		currentOp += 1
-
-		progChan = make(chan types.ProgCount)
-		go objects.HandleResources("Deployment", flinkNamespace, "Available", progChan)
-		utils.DisplayProgress(progChan, "creating flink deploymens", fmt.Sprintf("%d/%d", currentOp, totalOps))
-		currentOp += 1
-
-		Profile.Packages = append(Profile.Packages, helmPackage)
-	}
+	for i, step := range steps {
+		currentOp = i + 1
 
-	if monitoring {
-		var namespace string = "monitoring"
-		if err := objects.CreateNamespace(namespace); err != nil {
-			_ = fmt.Sprintf("%s", err)
-		}
-		node_endpoints, err := utils.GetNodeEndpoints()
-		if err != nil {
-			fmt.Println(err)
-			// handler error?...
+		// steps of a resumed init, and steps of adopted installs, are completed already
+		if Profile.Step(step.Name).Status == types.StepCompleted {
+			fmt.Printf("[%s] %s already completed, skipping\n", progressLabel(), step.Name)
+			continue
 		}
 
-		fmt.Print("please set your admin username for grafana: ")
-		var grafanaUser string
-		_, err = fmt.Scanln(&grafanaUser)
+		if component, ok := config.GetComponent(step.Name); ok {
+			if upToDate(clientset, component, step) {
+				fmt.Printf("[%s] %s already installed as configured, skipping\n", progressLabel(), step.Name)
+				recordStep(&Profile, step, step.Package)
+				if err := utils.SaveProfile(&Profile); err != nil {
+					fmt.Fprintf(os.Stderr, "%v\n", err)
+					return
+				}
+				continue
+			}
+		}
 
+		helmPackage, err := step.Install(&Profile)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error reading input: %v\n", err)
+			fmt.Println(err)
+			Profile.SetStep(step.Name, types.StepFailed, err)
+			if err := utils.SaveProfile(&Profile); err != nil {
+				fmt.Fprintf(os.Stderr, "%v\n", err)
+			}
+			fmt.Printf("init failed at step %s. run 'beamstack init --resume' to continue\n", step.Name)
 			return
 		}
 
-		fmt.Print("please set your admin password for grafana: ")
-		grafanaPassword, err := term.ReadPassword(fd)
-		if err != nil {
-			fmt.Println("Error reading password:", err)
+		recordStep(&Profile, step, helmPackage)
+		if err := utils.SaveProfile(&Profile); err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
 			return
 		}
+	}
+}
 
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error reading input: %v\n", err)
-			return
+// missingInputs returns the inputs init would prompt for.
+func missingInputs(profile types.Profiles) []string {
+	missing := []string{}
+
+	if step := profile.Step(types.ComponentMonitoring); step != nil && step.Status != types.StepCompleted {
+		credentials := grafanaCredentialsRef()
+		if credentials.ExistingSecret != "" {
+			return missing
 		}
-		var secretData = map[string][]byte{
-			"admin-user":     []byte(grafanaUser),
-			"admin-password": []byte(string(grafanaPassword)),
+		if credentials.Username == "" {
+			if ConfigFile != "" {
+				missing = append(missing, "components.monitoring.credentials.username")
+			} else {
+				missing = append(missing, "--grafana-user")
+			}
 		}
-		if err := objects.CreateSecret("grafana-admin-credentials", namespace, secretData); err != nil {
-			// fmt.Println(err)
-			// handler error
-			_ = fmt.Sprintf("%s", err)
+		if credentials.PasswordFile == "" && credentials.PasswordEnv == "" && os.Getenv(grafanaPasswordEnv) == "" {
+			if ConfigFile != "" {
+				missing = append(missing, fmt.Sprintf("components.monitoring.credentials.passwordFile or %s", grafanaPasswordEnv))
+			} else {
+				missing = append(missing, fmt.Sprintf("--grafana-password-file or %s", grafanaPasswordEnv))
+			}
 		}
+	}
+	return missing
+}
 
-		fmt.Println("\ninstalling monitoring stack")
-		values := types.GetKubePrometheusValues(node_endpoints)
-		monitoringhelmPackage := utils.InstallHelmPackage("kube-prometheus-stack", "", "https://prometheus-community.github.io/helm-charts", "", namespace, values)
-
-		progChan = make(chan types.ProgCount)
-		go objects.HandleResources("CustomResourceDefinition", "", "Established", progChan)
-		utils.DisplayProgress(progChan, "validating monitoring crds", fmt.Sprintf("%d/%d", currentOp, totalOps))
-		currentOp += 1
+// componentFlags are the flags a configuration file replaces.
+var componentFlags = []string{
+	"name", "default-operator", "flink-version", "spark-version", "flink", "spark", "monitoring",
+	"elasticsearch", "kafka", "object-store", "object-store-size", "grafana-user", "grafana-password-file",
+}
 
-		progChan = make(chan types.ProgCount)
-		go objects.HandleResources("Deployment", namespace, "Available", progChan)
-		utils.DisplayProgress(progChan, "creating monitoring deployments", fmt.Sprintf("%d/%d", currentOp, totalOps))
-		currentOp += 1
+// profileConfig returns the configuration of a new init, loaded from the
+// configuration file or built from the flags.
+func profileConfig(cmd *cobra.Command) (types.ProfileConfig, error) {
+	if ConfigFile != "" {
+		for _, flag := range componentFlags {
+			if cmd.Flags().Changed(flag) {
+				return types.ProfileConfig{}, fmt.Errorf("--%s cannot be used with --config. set it in the configuration file instead", flag)
+			}
+		}
 
-		Profile.Monitoring = &types.Monitoring{
-			Name: "kube-prometheus-stack",
+		profileConfig, err := utils.LoadProfileConfig(ConfigFile)
+		if err != nil {
+			return profileConfig, err
 		}
-		Profile.Packages = append(Profile.Packages, monitoringhelmPackage)
+		if profileConfig.Name == "" {
+			profileConfig.Name = uuid.NewString()
+		}
+		return profileConfig, nil
+	}
 
+	if Name == "" {
+		Name = uuid.NewString()
+	}
+	profileConfig := types.ProfileConfig{
+		Name: Name,
+		Components: map[string]types.ComponentConfig{
+			types.ComponentCertManager: {},
+		},
 	}
 
+	if Flink || !Spark {
+		profileConfig.Components[types.ComponentFlink] = types.ComponentConfig{Version: FlinkVersion}
+	}
+	if monitoring {
+		component := types.ComponentConfig{}
+		if grafanaUser != "" || grafanaPasswordFile != "" {
+			component.Credentials = &types.CredentialsRef{
+				Username:     grafanaUser,
+				PasswordFile: grafanaPasswordFile,
+			}
+		}
+		profileConfig.Components[types.ComponentMonitoring] = component
+	}
 	if elasticsearch {
-		fmt.Println("installing Elasticsearch")
+		profileConfig.Components[types.ComponentElasticsearch] = types.ComponentConfig{}
+	}
+	if kafka {
+		profileConfig.Components[types.ComponentKafka] = types.ComponentConfig{}
+	}
+	if objectStore {
+		profileConfig.Components[types.ComponentObjectStore] = types.ComponentConfig{Size: objectStoreSize}
+	}
 
-		if err := objects.CreateObject("https://download.elastic.co/downloads/eck/2.14.0/crds.yaml"); err != nil {
-			fmt.Printf("could not install elastic search crds: \n%s\n", err)
-			return
+	return profileConfig, profileConfig.Validate()
+}
+
+// applyValuesFiles merges the values files given as component=file.yaml over
+// the values of the configuration.
+func applyValuesFiles(profileConfig *types.ProfileConfig, files []string) error {
+	for _, file := range files {
+		name, path, ok := strings.Cut(file, "=")
+		if !ok || name == "" || path == "" {
+			return fmt.Errorf("invalid --values %s. expected component=file.yaml", file)
+		}
+		if !profileConfig.Has(name) {
+			return fmt.Errorf("invalid --values %s: %s is not installed", file, name)
 		}
-		progChan := make(chan types.ProgCount)
-		go objects.HandleResources("CustomResourceDefinition", "", "Established", progChan)
-		utils.DisplayProgress(progChan, "deploying elasticsearch crds", fmt.Sprintf("%d/%d", currentOp, totalOps))
-		currentOp += 1
 
-		if err := objects.CreateObject("https://download.elastic.co/downloads/eck/2.14.0/operator.yaml"); err != nil {
-			fmt.Printf("could not install elastic operator: \n%s\n", err)
-			return
+		values, err := utils.LoadValuesFile(path)
+		if err != nil {
+			return err
+		}
+		if profileConfig.Components == nil {
+			profileConfig.Components = map[string]types.ComponentConfig{}
 		}
-		progChan = make(chan types.ProgCount)
-		go objects.HandleResources("Pod", "elastic-system", "Ready", progChan)
-		utils.DisplayProgress(progChan, "deploying elastic search operator", fmt.Sprintf("%d/%d", currentOp, totalOps))
-		currentOp += 1
-
-		Profile.Packages = append(Profile.Packages, types.Package{
-			Name:    "elasticsearch",
-			Url:     "https://download.elastic.co/downloads/eck/2.14.0/operator.yaml",
-			Type:    "k8s",
-			Version: "2.14.0",
-			Dependencies: []*types.Package{
-				{
-					Name:    "crds/agents.agent.k8s.elastic.co",
-					Url:     "https://download.elastic.co/downloads/eck/2.14.0/crds.yaml",
-					Type:    "k8s",
-					Version: "2.14.0",
-				},
-				{
-					Name:    "crds/apmservers.apm.k8s.elastic.co",
-					Url:     "https://download.elastic.co/downloads/eck/2.14.0/crds.yaml",
-					Type:    "k8s",
-					Version: "2.14.0",
-				},
-				{
-					Name:    "crds/beats.beat.k8s.elastic.co",
-					Url:     "https://download.elastic.co/downloads/eck/2.14.0/crds.yaml",
-					Type:    "k8s",
-					Version: "2.14.0",
-				},
-				{
-					Name:    "crds/elasticmapsservers.maps.k8s.elastic.co",
-					Url:     "https://download.elastic.co/downloads/eck/2.14.0/crds.yaml",
-					Type:    "k8s",
-					Version: "2.14.0",
-				},
-				{
-					Name:    "crds/elasticsearchautoscalers.autoscaling.k8s.elastic.co",
-					Url:     "https://download.elastic.co/downloads/eck/2.14.0/crds.yaml",
-					Type:    "k8s",
-					Version: "2.14.0",
-				},
-				{
-					Name:    "crds/elasticsearches.elasticsearch.k8s.elastic.co",
-					Url:     "https://download.elastic.co/downloads/eck/2.14.0/crds.yaml",
-					Type:    "k8s",
-					Version: "2.14.0",
-				},
-				{
-					Name:    "crds/enterprisesearches.enterprisesearch.k8s.elastic.co",
-					Url:     "https://download.elastic.co/downloads/eck/2.14.0/crds.yaml",
-					Type:    "k8s",
-					Version: "2.14.0",
-				},
-				{
-					Name:    "crds/kibanas.kibana.k8s.elastic.co",
-					Url:     "https://download.elastic.co/downloads/eck/2.14.0/crds.yaml",
-					Type:    "k8s",
-					Version: "2.14.0",
-				},
-				{
-					Name:    "crds/logstashes.logstash.k8s.elastic.co",
-					Url:     "https://download.elastic.co/downloads/eck/2.14.0/crds.yaml",
-					Type:    "k8s",
-					Version: "2.14.0",
-				},
-				{
-					Name:    "crds/stackconfigpolicies.stackconfigpolicy.k8s.elastic.co",
-					Url:     "https://download.elastic.co/downloads/eck/2.14.0/crds.yaml",
-					Type:    "k8s",
-					Version: "2.14.0",
-				},
-			},
-		})
+		component := profileConfig.Component(name)
+		component.ValuesFile = path
+		component.Values = utils.MergeValues(component.Values, values)
+		profileConfig.Components[name] = component
+	}
+	return profileConfig.Validate()
+}
 
+// newProfile builds the profile of a new init from its configuration.
+func newProfile(profileConfig types.ProfileConfig) types.Profiles {
+	operators := types.Operator{}
+	if profileConfig.Has(types.ComponentFlink) {
+		operators.Flink = &types.OperatorDetails{
+			Version:   profileConfig.Version(types.ComponentFlink),
+			IsDefault: DefaultOperator == "flink",
+		}
 	}
 
-	contextsStringMap[currentContext] = Profile.Name
-	viper.Set("contexts", contextsStringMap)
-	if err := viper.WriteConfig(); err != nil {
-		fmt.Printf("Error writing config file: %v\n", err)
+	if Spark {
+		operators.Spark = &types.OperatorDetails{
+			Version:   SparkVersion,
+			IsDefault: DefaultOperator == "spark",
+		}
 	}
 
-	err = utils.SaveProfile(&Profile)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "%v\n", err)
-		return
+	// namespaces are recorded for every component, so commands resolve them from the profile
+	components := map[string]types.ComponentConfig{}
+	for _, component := range types.Components {
+		if profileConfig.Has(component.Name) {
+			componentConfig := profileConfig.Component(component.Name)
+			componentConfig.Namespace = profileConfig.Namespace(component.Name)
+			components[component.Name] = componentConfig
+		}
 	}
 
+	return types.Profiles{
+		Name:        profileConfig.Name,
+		Operators:   operators,
+		Monitoring:  nil,
+		Packages:    []types.Package{},
+		Components:  components,
+		Scheduling:  profileConfig.Scheduling,
+		Environment: utils.Environment,
+	}
+}
+
+// recordedConfig returns the configuration of a resumed init. Profiles
+// created before configurations were recorded fall back to their steps.
+func recordedConfig(profile types.Profiles) types.ProfileConfig {
+	if len(profile.Components) > 0 {
+		return profile.Config()
+	}
+
+	profileConfig := types.ProfileConfig{
+		Name:       profile.Name,
+		Components: map[string]types.ComponentConfig{},
+		Scheduling: profile.Scheduling,
+	}
+	for _, step := range profile.Steps {
+		component := types.ComponentConfig{}
+		if step.Name == types.ComponentFlink && profile.Operators.Flink != nil {
+			component.Version = profile.Operators.Flink.Version
+		}
+		profileConfig.Components[step.Name] = component
+	}
+	return profileConfig
+}
+
+// recordStep marks the step completed and records what it installed in the profile.
+func recordStep(profile *types.Profiles, step initStep, installed types.Package) {
+	packages := []types.Package{}
+	for _, pkg := range profile.Packages {
+		if pkg.Name != installed.Name {
+			packages = append(packages, pkg)
+		}
+	}
+	profile.Packages = append(packages, installed)
+
+	if step.Record != nil {
+		step.Record(profile)
+	}
+	profile.SetStep(step.Name, types.StepCompleted, nil)
 }
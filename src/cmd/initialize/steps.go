@@ -0,0 +1,520 @@
+package initialize
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+
+	doctor_handler "github.com/BeamStackProj/beamstack-cli/src/handlers/doctor"
+	"github.com/BeamStackProj/beamstack-cli/src/objects"
+	"github.com/BeamStackProj/beamstack-cli/src/types"
+	"github.com/BeamStackProj/beamstack-cli/src/utils"
+	"github.com/google/uuid"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/kubernetes"
+)
+
+// initStep is a named, resumable step of init installing one component.
+// Package is recorded in the profile when the step is skipped because the
+// component is already installed as configured, Install returns the package
+// it installed. Values returns the helm values of components installed from
+// a chart.
+type initStep struct {
+	Name    string
+	Package types.Package
+	Install func(profile *types.Profiles) (types.Package, error)
+	Values  func() map[string]interface{}
+	Record  func(profile *types.Profiles)
+}
+
+// initSteps returns the steps installing the components of the configuration, in order.
+func initSteps() []initStep {
+	steps := []initStep{}
+	for _, component := range types.Components {
+		if config.Has(component.Name) {
+			steps = append(steps, componentStep(component.Name))
+		}
+	}
+	return steps
+}
+
+// upToDate reports whether a component is healthy and installed with the
+// version and values of the configuration. Steps writing credentials given to
+// init always run, so the credentials are updated.
+func upToDate(clientset *kubernetes.Clientset, component types.Component, step initStep) bool {
+	if healthy, err := objects.ComponentHealthy(clientset, component); err != nil || !healthy {
+		return false
+	}
+
+	switch step.Name {
+	case types.ComponentMonitoring:
+		if grafanaCredentialsRef().ExistingSecret == "" {
+			return false
+		}
+	case types.ComponentObjectStore:
+		if credentials := config.Component(step.Name).Credentials; credentials != nil && credentials.ExistingSecret == "" {
+			return false
+		}
+	}
+
+	if step.Values != nil {
+		matches, err := utils.HelmReleaseMatches(step.Package.Name, step.Package.Namespace, step.Package.Version, step.Values())
+		return err == nil && matches
+	}
+
+	// manifests carry no values, the version is read from the image of the controller
+	install, found, err := doctor_handler.DetectInstall(clientset, step.Name, config.Namespace(step.Name))
+	if err != nil {
+		return false
+	}
+	return !found || step.Package.Version == "" || install.Version == step.Package.Version
+}
+
+func componentStep(name string) initStep {
+	version := config.Version(name)
+	namespace := config.Namespace(name)
+
+	switch name {
+	case types.ComponentCertManager:
+		return initStep{
+			Name:    name,
+			Package: types.CertManagerPackage(version),
+			Install: installCertManager,
+		}
+	case types.ComponentFlink:
+		flinkPackage := types.FlinkOperatorPackage(version)
+		flinkPackage.Namespace = namespace
+		return initStep{
+			Name:    name,
+			Package: flinkPackage,
+			Install: installFlink,
+			Values:  flinkValues,
+		}
+	case types.ComponentMonitoring:
+		return initStep{
+			Name: name,
+			Package: types.Package{
+				Name:      "kube-prometheus-stack",
+				Url:       types.PrometheusChartsRepo,
+				Type:      "helm",
+				Version:   version,
+				Namespace: namespace,
+			},
+			Install: installMonitoring,
+			Values:  monitoringValues,
+			Record: func(profile *types.Profiles) {
+				profile.Monitoring = &types.Monitoring{
+					Name: "kube-prometheus-stack",
+				}
+			},
+		}
+	case types.ComponentElasticsearch:
+		return initStep{
+			Name:    name,
+			Package: types.EckPackage(version),
+			Install: installElasticsearch,
+		}
+	case types.ComponentKafka:
+		return initStep{
+			Name:    name,
+			Package: types.KafkaPackage(namespace),
+			Install: installKafka,
+		}
+	case types.ComponentObjectStore:
+		return initStep{
+			Name: name,
+			Package: types.Package{
+				Name:      types.ObjectStoreRelease,
+				Url:       types.MinioChartsRepo,
+				Type:      "helm",
+				Version:   version,
+				Namespace: namespace,
+			},
+			Install: installObjectStore,
+			Values:  objectStoreValues,
+			Record: func(profile *types.Profiles) {
+				profile.ObjectStore = &types.ObjectStore{
+					Name:              types.ObjectStoreRelease,
+					Namespace:         namespace,
+					Endpoint:          types.ObjectStoreEndpoint(namespace),
+					CredentialsSecret: objectStoreSecret(),
+				}
+			},
+		}
+	}
+	return initStep{Name: name}
+}
+
+// artifactPath returns the bundled copy of a manifest when installing from a bundle.
+func artifactPath(source string) string {
+	if bundle != nil {
+		if bundled, ok := bundle.Path(source, ""); ok {
+			return bundled
+		}
+	}
+	return source
+}
+
+// applyArtifact applies a manifest, from the bundle when installing from one,
+// and keeps a copy of it for uninstall.
+func applyArtifact(source string) error {
+	return objects.CreateSavedObject(source, artifactPath(source))
+}
+
+// installChart installs a chart from its repository, or from the bundled
+// archive when installing from a bundle.
+func installChart(name string, url string, version string, namespace string, values *map[string]interface{}) types.Package {
+	if bundle != nil {
+		if chartPath, ok := bundle.Path(url, name); ok {
+			helmPackage := utils.InstallLocalHelmPackage(name, chartPath, version, namespace, values)
+			helmPackage.Url = url
+			return helmPackage
+		}
+	}
+	return utils.InstallHelmPackage(name, "", url, version, namespace, values)
+}
+
+func progressLabel() string {
+	return fmt.Sprintf("%d/%d", currentOp, totalOps)
+}
+
+func installCertManager(profile *types.Profiles) (types.Package, error) {
+	version := config.Version(types.ComponentCertManager)
+	fmt.Println("installing cert manager crds")
+
+	if err := applyArtifact(types.CertManagerUrl(version)); err != nil {
+		return types.Package{}, fmt.Errorf("could not install cert manager: \n%s", err)
+	}
+
+	progChan := make(chan types.ProgCount)
+	go objects.HandleResources("CustomResourceDefinition", "", "Established", progChan)
+	utils.DisplayProgress(progChan, "deploying crds", progressLabel())
+
+	progChan = make(chan types.ProgCount)
+	go objects.HandleResources("Deployment", config.Namespace(types.ComponentCertManager), "Available", progChan)
+	utils.DisplayProgress(progChan, "creating deployments", progressLabel())
+
+	return types.CertManagerPackage(version), nil
+}
+
+func installFlink(profile *types.Profiles) (types.Package, error) {
+	version := config.Version(types.ComponentFlink)
+	flinkNamespace := config.Namespace(types.ComponentFlink)
+
+	if err := objects.CreateNamespace(flinkNamespace); err != nil {
+		// handler error?...
+		_ = fmt.Sprintf("%s", err)
+	}
+	values := flinkValues()
+
+	fmt.Println("\ninstalling flink operator")
+	helmPackage := installChart("flink-kubernetes-operator", types.FlinkOperatorChartsRepo(version), version, flinkNamespace, &values)
+
+	progChan := make(chan types.ProgCount)
+	go objects.HandleResources("CustomResourceDefinition", "", "Established", progChan)
+	utils.DisplayProgress(progChan, "installing flink", progressLabel())
+
+	progChan = make(chan types.ProgCount)
+	go objects.HandleResources("Deployment", flinkNamespace, "Available", progChan)
+	utils.DisplayProgress(progChan, "creating flink deploymens", progressLabel())
+
+	return helmPackage, nil
+}
+
+// flinkValues returns the helm values of the flink operator.
+func flinkValues() map[string]interface{} {
+	// the operator only watches its own namespace, so operators of other environments do not compete for its clusters
+	values := map[string]interface{}{
+		"watchNamespaces": []interface{}{config.Namespace(types.ComponentFlink)},
+		"defaultConfiguration": map[string]interface{}{
+			"create": true,
+			"append": true,
+			"flink-conf.yaml": `
+              kubernetes.operator.metrics.reporter.prom.factory.class: org.apache.flink.metrics.prometheus.PrometheusReporterFactory
+              kubernetes.operator.metrics.reporter.prom.port: 9999
+              metrics.port: 9999
+            `,
+		},
+	}
+	return utils.MergeValues(values, config.Component(types.ComponentFlink).Values)
+}
+
+func installMonitoring(profile *types.Profiles) (types.Package, error) {
+	namespace := config.Namespace(types.ComponentMonitoring)
+	if err := objects.CreateNamespace(namespace); err != nil {
+		_ = fmt.Sprintf("%s", err)
+	}
+	credentials := grafanaCredentialsRef()
+	if credentials.ExistingSecret == "" {
+		grafanaUser, grafanaPassword, err := grafanaCredentials(credentials)
+		if err != nil {
+			return types.Package{}, err
+		}
+
+		var secretData = map[string][]byte{
+			"admin-user":     []byte(grafanaUser),
+			"admin-password": []byte(grafanaPassword),
+		}
+		if err := objects.ApplySecret(grafanaSecret(), namespace, secretData); err != nil {
+			return types.Package{}, fmt.Errorf("could not write grafana admin credentials %s: %v", grafanaSecret(), err)
+		}
+	}
+
+	fmt.Println("\ninstalling monitoring stack")
+	values := monitoringValues()
+	monitoringhelmPackage := installChart("kube-prometheus-stack", types.PrometheusChartsRepo, config.Version(types.ComponentMonitoring), namespace, &values)
+
+	progChan := make(chan types.ProgCount)
+	go objects.HandleResources("CustomResourceDefinition", "", "Established", progChan)
+	utils.DisplayProgress(progChan, "validating monitoring crds", progressLabel())
+
+	progChan = make(chan types.ProgCount)
+	go objects.HandleResources("Deployment", namespace, "Available", progChan)
+	utils.DisplayProgress(progChan, "creating monitoring deployments", progressLabel())
+
+	return monitoringhelmPackage, nil
+}
+
+// monitoringValues returns the helm values of the monitoring stack.
+func monitoringValues() map[string]interface{} {
+	node_endpoints, err := utils.GetNodeEndpoints()
+	if err != nil {
+		fmt.Println(err)
+		// handler error?...
+	}
+
+	values := utils.MergeValues(*types.GetKubePrometheusValues(node_endpoints), map[string]interface{}{
+		"grafana": map[string]interface{}{
+			"admin": map[string]interface{}{
+				"existingSecret": grafanaSecret(),
+			},
+		},
+	})
+	return utils.MergeValues(values, config.Component(types.ComponentMonitoring).Values)
+}
+
+// grafanaSecret returns the secret holding the grafana admin credentials.
+func grafanaSecret() string {
+	if secretName := grafanaCredentialsRef().ExistingSecret; secretName != "" {
+		return secretName
+	}
+	return "grafana-admin-credentials"
+}
+
+// grafanaCredentialsRef returns the grafana admin credentials of the
+// configuration, or the ones given with flags.
+func grafanaCredentialsRef() types.CredentialsRef {
+	if credentials := config.Component(types.ComponentMonitoring).Credentials; credentials != nil {
+		return *credentials
+	}
+	return types.CredentialsRef{
+		Username:     grafanaUser,
+		PasswordFile: grafanaPasswordFile,
+	}
+}
+
+// grafanaCredentials returns the grafana admin credentials from the
+// configuration or environment, prompting for whatever is missing.
+func grafanaCredentials(credentials types.CredentialsRef) (string, string, error) {
+	user := credentials.Username
+	if user == "" {
+		fmt.Print("please set your admin username for grafana: ")
+		if _, err := fmt.Scanln(&user); err != nil {
+			return "", "", fmt.Errorf("error reading input: %v", err)
+		}
+	}
+
+	if password, ok, err := readPassword(credentials); err != nil {
+		return "", "", err
+	} else if ok {
+		return user, password, nil
+	}
+
+	if password := os.Getenv(grafanaPasswordEnv); password != "" {
+		return user, password, nil
+	}
+
+	fmt.Print("please set your admin password for grafana: ")
+	password, err := term.ReadPassword(fd)
+	fmt.Println()
+	if err != nil {
+		return "", "", fmt.Errorf("error reading password: %v", err)
+	}
+	return user, string(password), nil
+}
+
+// readPassword returns the password of a credentials reference, and whether
+// the reference points to a file or environment variable holding one.
+func readPassword(credentials types.CredentialsRef) (string, bool, error) {
+	if credentials.PasswordFile != "" {
+		password, err := os.ReadFile(credentials.PasswordFile)
+		if err != nil {
+			return "", false, fmt.Errorf("could not read password file: %v", err)
+		}
+		return strings.TrimRight(string(password), "\r\n"), true, nil
+	}
+
+	if credentials.PasswordEnv != "" {
+		password := os.Getenv(credentials.PasswordEnv)
+		if password == "" {
+			return "", false, fmt.Errorf("environment variable %s is not set", credentials.PasswordEnv)
+		}
+		return password, true, nil
+	}
+	return "", false, nil
+}
+
+func installElasticsearch(profile *types.Profiles) (types.Package, error) {
+	version := config.Version(types.ComponentElasticsearch)
+	fmt.Println("installing Elasticsearch")
+
+	if err := applyArtifact(types.EckCrdsUrl(version)); err != nil {
+		return types.Package{}, fmt.Errorf("could not install elastic search crds: \n%s", err)
+	}
+	progChan := make(chan types.ProgCount)
+	go objects.HandleResources("CustomResourceDefinition", "", "Established", progChan)
+	utils.DisplayProgress(progChan, "deploying elasticsearch crds", progressLabel())
+
+	if err := applyArtifact(types.EckOperatorUrl(version)); err != nil {
+		return types.Package{}, fmt.Errorf("could not install elastic operator: \n%s", err)
+	}
+	progChan = make(chan types.ProgCount)
+	go objects.HandleResources("Pod", config.Namespace(types.ComponentElasticsearch), "Ready", progChan)
+	utils.DisplayProgress(progChan, "deploying elastic search operator", progressLabel())
+
+	return types.EckPackage(version), nil
+}
+
+func installKafka(profile *types.Profiles) (types.Package, error) {
+	namespace := config.Namespace(types.ComponentKafka)
+	fmt.Println("installing Kafka")
+
+	if err := objects.CreateNamespace(namespace); err != nil {
+		_ = fmt.Sprintf("%s", err)
+	}
+
+	if err := applyArtifact(types.StrimziInstallUrl(namespace)); err != nil {
+		return types.Package{}, fmt.Errorf("could not install strimzi kafka operator: \n%s", err)
+	}
+	progChan := make(chan types.ProgCount)
+	go objects.HandleResources("CustomResourceDefinition", "", "Established", progChan)
+	utils.DisplayProgress(progChan, "deploying kafka crds", progressLabel())
+
+	progChan = make(chan types.ProgCount)
+	go objects.HandleResources("Deployment", namespace, "Available", progChan)
+	utils.DisplayProgress(progChan, "deploying strimzi kafka operator", progressLabel())
+
+	return types.KafkaPackage(namespace), nil
+}
+
+func installObjectStore(profile *types.Profiles) (types.Package, error) {
+	namespace := config.Namespace(types.ComponentObjectStore)
+	flinkNamespace := config.Namespace(types.ComponentFlink)
+	component := config.Component(types.ComponentObjectStore)
+	fmt.Println("installing object store")
+
+	if err := objects.CreateNamespace(namespace); err != nil {
+		_ = fmt.Sprintf("%s", err)
+	}
+
+	// pipeline jobs and migration pods read the credentials from the flink namespace
+	secretName := objectStoreSecret()
+	if component.Credentials != nil && component.Credentials.ExistingSecret != "" {
+		clientset, err := kubernetes.NewForConfig(utils.GetKubeConfig())
+		if err != nil {
+			return types.Package{}, err
+		}
+		if err := objects.CopySecret(clientset, secretName, namespace, secretName, flinkNamespace); err != nil {
+			return types.Package{}, fmt.Errorf("could not copy object store credentials %s: %v", secretName, err)
+		}
+	} else if err := writeObjectStoreCredentials(component.Credentials, secretName, namespace, flinkNamespace); err != nil {
+		return types.Package{}, err
+	}
+
+	values := objectStoreValues()
+	objectStorePackage := installChart(types.ObjectStoreRelease, types.MinioChartsRepo, config.Version(types.ComponentObjectStore), namespace, &values)
+
+	progChan := make(chan types.ProgCount)
+	go objects.HandleResources("Deployment", namespace, "Available", progChan)
+	utils.DisplayProgress(progChan, "deploying object store", progressLabel())
+
+	return objectStorePackage, nil
+}
+
+// writeObjectStoreCredentials writes the object store credentials to the
+// secrets of the object store and of the flink namespace. A password given
+// to init replaces the credentials of an earlier init. Otherwise the
+// credentials of an earlier init are kept, as the object store runs with
+// them, and new ones are generated on the first init.
+func writeObjectStoreCredentials(credentials *types.CredentialsRef, secretName string, namespace string, flinkNamespace string) error {
+	user, password := "beamstack", ""
+	if credentials != nil {
+		if credentials.Username != "" {
+			user = credentials.Username
+		}
+		configured, ok, err := readPassword(*credentials)
+		if err != nil {
+			return err
+		} else if ok {
+			password = configured
+		}
+	}
+
+	if password == "" {
+		clientset, err := kubernetes.NewForConfig(utils.GetKubeConfig())
+		if err != nil {
+			return err
+		}
+		if err := objects.CopySecret(clientset, secretName, namespace, secretName, flinkNamespace); err == nil {
+			return nil
+		} else if !errors.IsNotFound(err) {
+			return fmt.Errorf("could not copy object store credentials %s: %v", secretName, err)
+		}
+		password = uuid.NewString()
+	}
+
+	var secretData = map[string][]byte{
+		types.ObjectStoreUserKey:     []byte(user),
+		types.ObjectStorePasswordKey: []byte(password),
+	}
+	for _, secretNamespace := range []string{namespace, flinkNamespace} {
+		if err := objects.ApplySecret(secretName, secretNamespace, secretData); err != nil {
+			return fmt.Errorf("could not write object store credentials %s: %v", secretName, err)
+		}
+	}
+	return nil
+}
+
+// objectStoreValues returns the helm values of the object store.
+func objectStoreValues() map[string]interface{} {
+	component := config.Component(types.ComponentObjectStore)
+	size := component.Size
+	if size == "" {
+		size = objectStoreSize
+	}
+	values := map[string]interface{}{
+		"fullnameOverride": types.ObjectStoreRelease,
+		"mode":             "standalone",
+		"replicas":         1,
+		"existingSecret":   objectStoreSecret(),
+		"persistence": map[string]interface{}{
+			"size": size,
+		},
+		"resources": map[string]interface{}{
+			"requests": map[string]interface{}{
+				"memory": "512Mi",
+			},
+		},
+	}
+	return utils.MergeValues(values, component.Values)
+}
+
+// objectStoreSecret returns the secret holding the object store credentials.
+func objectStoreSecret() string {
+	if credentials := config.Component(types.ComponentObjectStore).Credentials; credentials != nil && credentials.ExistingSecret != "" {
+		return credentials.ExistingSecret
+	}
+	return types.ObjectStoreSecret
+}
@@ -1,18 +1,15 @@
 package open
 
 import (
-	"context"
 	"fmt"
 	"os"
 	"os/signal"
 	"syscall"
 
+	"github.com/BeamStackProj/beamstack-cli/src/objects"
 	"github.com/BeamStackProj/beamstack-cli/src/types"
 	"github.com/BeamStackProj/beamstack-cli/src/utils"
 	"github.com/spf13/cobra"
-	v1 "k8s.io/api/core/v1"
-	"k8s.io/apimachinery/pkg/api/errors"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 )
 
@@ -59,19 +56,8 @@ var DashboardCmd = &cobra.Command{
 			return
 		}
 
-		svc := v1.Service{
-			ObjectMeta: metav1.ObjectMeta{
-				Name:      "grafana",
-				Namespace: "monitoring",
-			},
-		}
-
-		GrafanaSvc, err := clientset.CoreV1().Services("monitoring").Get(context.TODO(), svc.Name, metav1.GetOptions{})
-
-		if errors.IsNotFound(err) {
-			fmt.Printf("could not find service/monitoring/%s\n", svc.Name)
-			return
-		} else if err != nil {
+		GrafanaSvc, err := objects.FindGrafanaService(clientset, profile)
+		if err != nil {
 			fmt.Println(err)
 			return
 		}
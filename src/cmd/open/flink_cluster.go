@@ -7,6 +7,7 @@ import (
 	"os/signal"
 	"syscall"
 
+	"github.com/BeamStackProj/beamstack-cli/src/objects"
 	"github.com/BeamStackProj/beamstack-cli/src/types"
 	"github.com/BeamStackProj/beamstack-cli/src/utils"
 	"github.com/spf13/cobra"
@@ -65,17 +66,22 @@ var FlinkClusterCmd = &cobra.Command{
 			return
 		}
 
+		namespace := utils.WorkloadNamespace(profile.Namespace(types.ComponentFlink))
+		if _, err := objects.GetFlinkCluster(profile, args[0], namespace); err != nil {
+			fmt.Println(err)
+			return
+		}
 		svc := v1.Service{
 			ObjectMeta: metav1.ObjectMeta{
 				Name:      fmt.Sprintf("%s-rest", args[0]),
-				Namespace: "flink",
+				Namespace: namespace,
 			},
 		}
 
-		FlinkClusterSvc, err := clientset.CoreV1().Services("flink").Get(context.TODO(), svc.Name, metav1.GetOptions{})
+		FlinkClusterSvc, err := clientset.CoreV1().Services(namespace).Get(context.TODO(), svc.Name, metav1.GetOptions{})
 
 		if errors.IsNotFound(err) {
-			fmt.Printf("could not find service/flink/%s\n", svc.Name)
+			fmt.Printf("could not find service/%s/%s\n", namespace, svc.Name)
 			return
 		} else if err != nil {
 			fmt.Println(err)
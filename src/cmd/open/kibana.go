@@ -0,0 +1,135 @@
+package open
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/BeamStackProj/beamstack-cli/src/types"
+	"github.com/BeamStackProj/beamstack-cli/src/utils"
+	"github.com/spf13/cobra"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Description and Examples for opening kibana
+var (
+	openKibanaLongDesc = utils.LongDesc(`
+		This command opens up the Kibana GUI of an elasticsearch cluster and forward it to a specified local port.
+		`)
+)
+
+// KibanaCmd represents the open kibana command
+var KibanaCmd = &cobra.Command{
+	Use:   "kibana [Name]",
+	Short: "opens up kibana ui",
+	Long:  openKibanaLongDesc,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			return fmt.Errorf("command requires exactly one argument: Kibana Name. Provided %d arguments", len(args))
+		}
+		return nil
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		LocalPort, err := cmd.Flags().GetUint16("localport")
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		TargetPort, err := cmd.Flags().GetUint16("targetport")
+
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		if _, err := utils.ValidateCluster(); err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		clientset, err := kubernetes.NewForConfig(config)
+
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		kibanaNamespace := utils.WorkloadNamespace("default")
+		svc := v1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      fmt.Sprintf("%s-kb-http", args[0]),
+				Namespace: kibanaNamespace,
+			},
+		}
+
+		KibanaSvc, err := clientset.CoreV1().Services(kibanaNamespace).Get(context.TODO(), svc.Name, metav1.GetOptions{})
+
+		if errors.IsNotFound(err) {
+			fmt.Printf("could not find service/%s/%s\n", kibanaNamespace, svc.Name)
+			return
+		} else if err != nil {
+			fmt.Println(err)
+			return
+		}
+		wg.Add(1)
+
+		sigs := make(chan os.Signal, 1)
+		signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
+
+		go func() {
+			<-sigs
+			close(stopCh)
+			wg.Done()
+		}()
+
+		go func() {
+			err := utils.PortForwardSvc(
+				clientset,
+				types.PortForwardASVCRequest{
+					PortForward: types.PortForward{
+						PodPort:   TargetPort,
+						LocalPort: LocalPort,
+						Streams:   stream,
+						StopCh:    stopCh,
+						ReadyCh:   readyCh,
+					},
+					Service: *KibanaSvc,
+				},
+			)
+
+			if err != nil {
+				panic(err)
+			}
+		}()
+
+		fmt.Printf("kibana is served over https with a self-signed certificate: https://localhost:%d\n", LocalPort)
+		println("Port forwarding is ready to get traffic. insert 'q' to stop")
+
+		go func() {
+			var quitC string
+			for {
+				fmt.Scanln(&quitC)
+				if quitC == "q" {
+					break
+				} else {
+					fmt.Printf("Unknown command %s\n", quitC)
+				}
+			}
+			wg.Done()
+		}()
+
+		wg.Wait()
+	},
+}
+
+func init() {
+	KibanaCmd.Flags().Uint16("targetport", 5601, "target container port")
+	KibanaCmd.Flags().Uint16("localport", 5601, "This port will be forwarded to the target port on the cluster")
+
+}
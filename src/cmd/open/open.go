@@ -35,4 +35,5 @@ var OpenCmd = &cobra.Command{
 func init() {
 	OpenCmd.AddCommand(FlinkClusterCmd)
 	OpenCmd.AddCommand(DashboardCmd)
+	OpenCmd.AddCommand(KibanaCmd)
 }
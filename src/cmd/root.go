@@ -6,11 +6,18 @@ package cmd
 import (
 	"os"
 
+	"github.com/BeamStackProj/beamstack-cli/src/cmd/bundle"
 	"github.com/BeamStackProj/beamstack-cli/src/cmd/create"
 	"github.com/BeamStackProj/beamstack-cli/src/cmd/deploy"
+	"github.com/BeamStackProj/beamstack-cli/src/cmd/doctor"
+	"github.com/BeamStackProj/beamstack-cli/src/cmd/expose"
+	"github.com/BeamStackProj/beamstack-cli/src/cmd/flink"
+	"github.com/BeamStackProj/beamstack-cli/src/cmd/get"
 	"github.com/BeamStackProj/beamstack-cli/src/cmd/info"
 	"github.com/BeamStackProj/beamstack-cli/src/cmd/initialize"
 	"github.com/BeamStackProj/beamstack-cli/src/cmd/open"
+	"github.com/BeamStackProj/beamstack-cli/src/cmd/uninstall"
+	"github.com/BeamStackProj/beamstack-cli/src/cmd/upgrade"
 	"github.com/BeamStackProj/beamstack-cli/src/utils"
 	"github.com/spf13/cobra"
 )
@@ -33,8 +40,16 @@ func Execute() {
 
 func addSubCommandPallets() {
 	rootCmd.AddCommand(initialize.InitCmd)
+	rootCmd.AddCommand(doctor.DoctorCmd)
+	rootCmd.AddCommand(uninstall.UninstallCmd)
+	rootCmd.AddCommand(upgrade.UpgradeCmd)
 	rootCmd.AddCommand(create.CreateCmd)
 	rootCmd.AddCommand(deploy.DeployCmd)
+	rootCmd.AddCommand(get.GetCmd)
+	rootCmd.AddCommand(flink.FlinkCmd)
+	rootCmd.AddCommand(expose.ExposeCmd)
+	rootCmd.AddCommand(expose.UnexposeCmd)
+	rootCmd.AddCommand(bundle.BundleCmd)
 	rootCmd.AddCommand(info.InfoCmd)
 	rootCmd.AddCommand(open.OpenCmd)
 	rootCmd.AddCommand(VersionCmd)
@@ -42,6 +57,8 @@ func addSubCommandPallets() {
 
 func init() {
 	cobra.OnInitialize(utils.InitConfig)
+	rootCmd.PersistentFlags().StringVar(&utils.Namespace, "namespace", utils.Namespace, "Namespace of user workloads such as clusters and pipelines. Defaults to the namespace of the component running them.")
+	rootCmd.PersistentFlags().StringVar(&utils.Environment, "env", utils.Environment, "Beamstack environment of the cluster to use. Environments share cluster wide components, and get their own namespaces and operators.")
 	addSubCommandPallets()
 
 }
@@ -0,0 +1,313 @@
+/*
+Copyright © 2024 MavenCode <opensource-dev@mavencode.com>
+*/
+package uninstall
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/BeamStackProj/beamstack-cli/src/objects"
+	"github.com/BeamStackProj/beamstack-cli/src/types"
+	"github.com/BeamStackProj/beamstack-cli/src/utils"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var (
+	uninstallLongDesc = utils.LongDesc(`
+		Remove everything 'beamstack init' installed on the current cluster, in the
+		reverse order of installation. Helm releases are uninstalled and manifests
+		are deleted. Custom resource definitions are kept unless --crds is given,
+		as deleting them deletes every resource of their kinds.
+
+		Once every package is removed, the cluster is unmapped from its profile
+		and the profile file is deleted. An interrupted uninstall can be run again
+		to remove the remaining packages.
+
+		Only the environment selected with --env is removed. Packages still used
+		by other environments of the cluster, such as cert-manager, are kept, and
+		only the resources of the environment are checked for. Existing installs
+		adopted by init are kept as well.
+
+		Manifests are deleted from the copies init keeps in ~/.beamstack/manifests.
+		Clusters initialized from a bundle on another machine can be uninstalled
+		without internet access by passing the bundle with --bundle.
+		`)
+
+	uninstallExample = utils.Examples(`
+		# Remove beamstack from the current cluster
+		beamstack uninstall
+
+		# Remove beamstack along with its custom resource definitions, without prompting
+		beamstack uninstall --crds --yes
+		`)
+
+	crds       bool   = false
+	yes        bool   = false
+	force      bool   = false
+	bundlePath string = ""
+	bundle     *utils.Bundle
+)
+
+// managedResources are the custom resources reconciled by the operators of a
+// package. They have to be deleted while their operator is still running.
+var managedResources = map[string][]schema.GroupVersionResource{
+	"flink-kubernetes-operator": {types.FlinkDeploymentGVR},
+	"elasticsearch":             {types.EsGVR},
+	"strimzi-kafka-operator":    {types.KafkaGVR},
+}
+
+// UninstallCmd represents the uninstall command
+var UninstallCmd = &cobra.Command{
+	Use:     "uninstall",
+	Short:   "Remove beamstack from cluster",
+	Long:    uninstallLongDesc,
+	Example: uninstallExample,
+	Args:    cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		currentContext, err := utils.GetCurrentContext()
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		profile, err := utils.ValidateCluster()
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		if bundlePath != "" {
+			bundle, err = utils.OpenBundle(bundlePath)
+			if err != nil {
+				fmt.Println(err)
+				return
+			}
+			defer bundle.Close()
+		}
+
+		// packages shared with other environments of the cluster stay installed
+		others := otherEnvironments(currentContext, profile)
+		if crds && len(others) > 0 {
+			fmt.Printf("custom resource definitions are shared with the environments %s of this cluster and cannot be deleted\n", strings.Join(environmentNames(others), ", "))
+			return
+		}
+		packages := []types.Package{}
+		for _, pkg := range profile.Packages {
+			if pkg.Type == "external" {
+				fmt.Printf("keeping %s, installed outside beamstack\n", pkg.Name)
+				continue
+			}
+			if environment, ok := sharedPackage(others, pkg); ok {
+				fmt.Printf("keeping %s, used by environment %s\n", pkg.Name, environment)
+				continue
+			}
+			packages = append(packages, pkg)
+		}
+
+		if !force {
+			remaining, err := remainingResources(profile, packages, len(others) > 0)
+			if err != nil {
+				fmt.Println(err)
+				return
+			}
+			if len(remaining) > 0 {
+				fmt.Printf("cluster still has resources managed by beamstack operators: %s\n", strings.Join(remaining, ", "))
+				fmt.Println("delete them first, or pass --force to uninstall anyway")
+				return
+			}
+		}
+
+		if !yes {
+			if !term.IsTerminal(int(os.Stdin.Fd())) {
+				fmt.Println("stdin is not a terminal. pass --yes to uninstall")
+				return
+			}
+			fmt.Printf("This removes every package of profile %s from environment %s of context %s. Continue? (y/n) ", profile.Name, profile.EnvironmentName(), currentContext)
+
+			var userInput string
+			if _, err := fmt.Scanln(&userInput); err != nil || strings.ToLower(userInput) != "y" {
+				fmt.Println("Aborting...")
+				return
+			}
+		}
+
+		for i := len(packages) - 1; i >= 0; i-- {
+			pkg := packages[i]
+			fmt.Printf("[%d/%d] removing %s\n", len(packages)-i, len(packages), pkg.Name)
+
+			if err := uninstallPackage(pkg); err != nil {
+				fmt.Println(err)
+				fmt.Println("uninstall failed. run 'beamstack uninstall' again to remove the remaining packages")
+				return
+			}
+
+			profile.Packages = removePackage(profile.Packages, pkg)
+			if err := utils.SaveProfile(&profile); err != nil {
+				fmt.Println(err)
+				return
+			}
+		}
+
+		if err := utils.SetEnvironmentProfile(currentContext, profile.EnvironmentName(), ""); err != nil {
+			fmt.Printf("Error writing config file: %v\n", err)
+			return
+		}
+
+		// profiles created from a configuration file can be shared by several clusters
+		if utils.ProfileInUse(profile.Name) {
+			fmt.Printf("beamstack removed from %s. profile %s is kept as other clusters use it\n", currentContext, profile.Name)
+			return
+		}
+		if err := utils.DeleteProfile(profile.Name); err != nil {
+			fmt.Println(err)
+			return
+		}
+		fmt.Printf("beamstack removed from %s\n", currentContext)
+	},
+}
+
+func init() {
+	UninstallCmd.Flags().BoolVar(&crds, "crds", crds, "If specified, also delete the custom resource definitions of the packages")
+	UninstallCmd.Flags().BoolVarP(&yes, "yes", "y", yes, "If specified, do not prompt for confirmation")
+	UninstallCmd.Flags().BoolVar(&force, "force", force, "If specified, uninstall even if flink clusters or other operator managed resources remain")
+	UninstallCmd.Flags().StringVar(&bundlePath, "bundle", bundlePath, "Path to the bundle init installed from. Manifests missing from ~/.beamstack/manifests are read from the bundle instead of being downloaded.")
+}
+
+// uninstallPackage removes a package recorded in the profile. Manifests of k8s
+// packages are deleted in the reverse order they were applied in, from the
+// copies kept by init or the bundle when there are any.
+func uninstallPackage(pkg types.Package) error {
+	switch pkg.Type {
+	case "helm":
+		crdManifests, err := utils.UninstallHelmPackage(pkg.Name, pkg.Namespace)
+		if err != nil {
+			return err
+		}
+		if crds {
+			for _, manifest := range crdManifests {
+				if err := objects.DeleteManifest(manifest, true); err != nil {
+					return err
+				}
+			}
+		}
+	case "k8s":
+		manifests := []string{pkg.Url}
+		for _, dependency := range pkg.Dependencies {
+			if dependency.Type == "k8s" && !contains(manifests, dependency.Url) {
+				manifests = append(manifests, dependency.Url)
+			}
+		}
+		for i := len(manifests) - 1; i >= 0; i-- {
+			manifest := manifests[i]
+			if _, saved := utils.SavedManifest(manifest); !saved && bundle != nil {
+				if bundled, ok := bundle.Path(manifest, ""); ok {
+					manifest = bundled
+				}
+			}
+			if err := objects.DeleteObject(manifest, crds); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("package %s has unknown type %q", pkg.Name, pkg.Type)
+	}
+	return nil
+}
+
+// remainingResources lists the custom resources left on the cluster that
+// would lose their operator. When other environments share the cluster, only
+// the resources of the environment of the profile are listed.
+func remainingResources(profile types.Profiles, packages []types.Package, shared bool) ([]string, error) {
+	remaining := []string{}
+	for _, pkg := range packages {
+		for _, gvr := range managedResources[pkg.Name] {
+			items, err := objects.ListDynamicResources(gvr, "")
+			if errors.IsNotFound(err) {
+				continue
+			} else if err != nil {
+				return nil, err
+			}
+			for _, item := range items {
+				if shared && !ownedResource(profile, pkg, item.GetNamespace(), item.GetLabels()) {
+					continue
+				}
+				remaining = append(remaining, fmt.Sprintf("%s %s/%s", gvr.Resource, item.GetNamespace(), item.GetName()))
+			}
+		}
+	}
+	return remaining, nil
+}
+
+// ownedResource reports whether a resource managed by the operator of a
+// package belongs to the environment of the profile: it is labeled with the
+// environment, or predates environments and lives in the namespace of the
+// package. Unlabeled resources of packages without a namespace belong to all.
+func ownedResource(profile types.Profiles, pkg types.Package, namespace string, labels map[string]string) bool {
+	if _, ok := labels[types.ProfileEnvironmentLabel]; ok {
+		return profile.Owns(labels)
+	}
+	return pkg.Namespace == "" || namespace == pkg.Namespace
+}
+
+// otherEnvironments returns the profiles of the other environments of the
+// kube context, keyed by environment.
+func otherEnvironments(context string, profile types.Profiles) map[string]types.Profiles {
+	others := map[string]types.Profiles{}
+	for environment, name := range utils.EnvironmentProfiles(context) {
+		if environment == profile.EnvironmentName() {
+			continue
+		}
+		other, err := utils.GetProfile(name)
+		if err != nil {
+			continue
+		}
+		others[environment] = other
+	}
+	return others
+}
+
+// sharedPackage returns the environment still using a package, if any.
+func sharedPackage(others map[string]types.Profiles, pkg types.Package) (string, bool) {
+	for _, environment := range environmentNames(others) {
+		for _, installed := range others[environment].Packages {
+			if installed.Name == pkg.Name && installed.Namespace == pkg.Namespace {
+				return environment, true
+			}
+		}
+	}
+	return "", false
+}
+
+func environmentNames(profiles map[string]types.Profiles) []string {
+	names := []string{}
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func removePackage(packages []types.Package, pkg types.Package) []types.Package {
+	kept := []types.Package{}
+	for _, installed := range packages {
+		if installed.Name != pkg.Name || installed.Namespace != pkg.Namespace {
+			kept = append(kept, installed)
+		}
+	}
+	return kept
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,336 @@
+/*
+Copyright © 2024 MavenCode <opensource-dev@mavencode.com>
+*/
+package upgrade
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/BeamStackProj/beamstack-cli/src/objects"
+	"github.com/BeamStackProj/beamstack-cli/src/types"
+	"github.com/BeamStackProj/beamstack-cli/src/utils"
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/kubernetes"
+)
+
+var (
+	upgradeLongDesc = utils.LongDesc(`
+		Upgrade the components installed by 'beamstack init' on the current cluster.
+		Helm releases are upgraded in place keeping the values they were installed
+		with, and manifests are applied over the installed release. Custom resource
+		definitions are updated before the operators using them.
+
+		Without a component, every installed component is upgraded to the version
+		this release of beamstack installs. --to selects another version of a
+		single component. Existing installs adopted by init are managed outside
+		beamstack and are not upgraded.
+		`)
+
+	upgradeExample = utils.Examples(`
+		# Show what upgrading every component would change
+		beamstack upgrade --plan
+
+		# Upgrade the flink operator to a given version
+		beamstack upgrade flink-operator --to 1.9.0
+		`)
+
+	to   string = ""
+	plan bool   = false
+)
+
+const rolloutTimeout = 10 * time.Minute
+
+// upgrader upgrades the package of a component to a version.
+type upgrader struct {
+	Package string
+	Upgrade func(pkg types.Package, version string) (types.Package, error)
+}
+
+var upgraders = map[string]upgrader{
+	types.ComponentCertManager: {
+		Package: "cert-manager",
+		Upgrade: func(pkg types.Package, version string) (types.Package, error) {
+			if err := applyManifest(types.CertManagerUrl(version)); err != nil {
+				return pkg, err
+			}
+			return types.CertManagerPackage(version), nil
+		},
+	},
+	types.ComponentFlink: {
+		Package: "flink-kubernetes-operator",
+		Upgrade: func(pkg types.Package, version string) (types.Package, error) {
+			return upgradeChart(pkg, types.FlinkOperatorChartsRepo(version), version)
+		},
+	},
+	types.ComponentMonitoring: {
+		Package: "kube-prometheus-stack",
+		Upgrade: func(pkg types.Package, version string) (types.Package, error) {
+			return upgradeChart(pkg, types.PrometheusChartsRepo, version)
+		},
+	},
+	types.ComponentElasticsearch: {
+		Package: "elasticsearch",
+		Upgrade: func(pkg types.Package, version string) (types.Package, error) {
+			if err := applyManifest(types.EckCrdsUrl(version)); err != nil {
+				return pkg, err
+			}
+			if err := applyManifest(types.EckOperatorUrl(version)); err != nil {
+				return pkg, err
+			}
+			return types.EckPackage(version), nil
+		},
+	},
+	types.ComponentKafka: {
+		Package: "strimzi-kafka-operator",
+		Upgrade: func(pkg types.Package, version string) (types.Package, error) {
+			if version != "latest" {
+				return pkg, fmt.Errorf("the strimzi kafka operator can only be upgraded to its latest release")
+			}
+			namespace := pkg.Namespace
+			if namespace == "" {
+				namespace = types.KafkaNamespace
+			}
+			if err := applyManifest(types.StrimziInstallUrl(namespace)); err != nil {
+				return pkg, err
+			}
+			return types.KafkaPackage(namespace), nil
+		},
+	},
+	types.ComponentObjectStore: {
+		Package: types.ObjectStoreRelease,
+		Upgrade: func(pkg types.Package, version string) (types.Package, error) {
+			return upgradeChart(pkg, types.MinioChartsRepo, version)
+		},
+	},
+}
+
+// UpgradeCmd represents the upgrade command
+var UpgradeCmd = &cobra.Command{
+	Use:     "upgrade [component]",
+	Short:   "Upgrade the components installed on cluster",
+	Long:    upgradeLongDesc,
+	Example: upgradeExample,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) > 1 {
+			return fmt.Errorf("upgrade accepts at most one component")
+		}
+		if len(args) == 1 {
+			if _, ok := upgraders[args[0]]; !ok {
+				return fmt.Errorf("unknown component %s. valid components are %s", args[0], strings.Join(componentNames(), ", "))
+			}
+		} else if to != "" {
+			return fmt.Errorf("--to requires a component")
+		}
+		return nil
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		profile, err := utils.ValidateCluster()
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		selected := componentNames()
+		if len(args) == 1 {
+			selected = []string{args[0]}
+		}
+
+		changes := []change{}
+		for _, name := range selected {
+			pkg, ok := findPackage(profile, upgraders[name].Package)
+			if !ok {
+				if len(args) == 1 {
+					fmt.Printf("%s is not installed on this cluster\n", name)
+					return
+				}
+				continue
+			}
+			if pkg.Type == "external" {
+				if len(args) == 1 {
+					fmt.Printf("%s is managed outside beamstack and cannot be upgraded\n", name)
+					return
+				}
+				continue
+			}
+
+			target := strings.TrimPrefix(to, "v")
+			if target == "" {
+				component, _ := types.GetComponent(name)
+				target = component.Version
+			}
+			changes = append(changes, newChange(name, pkg, target))
+		}
+
+		fmt.Printf("%-16s %-26s %-12s %-12s %s\n", "COMPONENT", "PACKAGE", "INSTALLED", "TARGET", "ACTION")
+		for _, c := range changes {
+			fmt.Printf("%-16s %-26s %-12s %-12s %s\n", c.Component, c.Package.Name, displayVersion(c.Installed), displayVersion(c.Target), c.Action())
+		}
+		if plan {
+			return
+		}
+
+		clientset, err := kubernetes.NewForConfig(utils.GetKubeConfig())
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		for _, c := range changes {
+			if c.UpToDate() {
+				continue
+			}
+
+			fmt.Printf("\nupgrading %s to %s\n", c.Component, displayVersion(c.Target))
+			upgraded, err := upgraders[c.Component].Upgrade(c.Package, c.Target)
+			if err != nil {
+				fmt.Println(err)
+				fmt.Printf("upgrade failed at %s\n", c.Component)
+				return
+			}
+
+			fmt.Printf("waiting for %s to become ready\n", c.Component)
+			component, _ := profile.Config().GetComponent(c.Component)
+			if err := objects.WaitForComponentRollout(clientset, component, rolloutTimeout); err != nil {
+				fmt.Println(err)
+				fmt.Printf("upgrade failed at %s\n", c.Component)
+				return
+			}
+
+			replacePackage(&profile, upgraded)
+			if c.Component == types.ComponentFlink && profile.Operators.Flink != nil {
+				profile.Operators.Flink.Version = upgraded.Version
+			}
+			if component, ok := profile.Components[c.Component]; ok {
+				component.Version = upgraded.Version
+				profile.Components[c.Component] = component
+			}
+			if err := utils.SaveProfile(&profile); err != nil {
+				fmt.Fprintf(os.Stderr, "%v\n", err)
+				return
+			}
+			fmt.Printf("%s upgraded to %s\n", c.Component, displayVersion(upgraded.Version))
+		}
+	},
+}
+
+func init() {
+	UpgradeCmd.Flags().StringVar(&to, "to", to, "Version to upgrade the component to. Defaults to the version this release of beamstack installs.")
+	UpgradeCmd.Flags().BoolVar(&plan, "plan", plan, "If specified, only show what would be upgraded")
+}
+
+// change is the upgrade of an installed package to a target version.
+type change struct {
+	Component string
+	Package   types.Package
+	Installed string
+	Target    string
+}
+
+func newChange(component string, pkg types.Package, target string) change {
+	installed := pkg.Version
+	if installed == "" && pkg.Type == "helm" {
+		// charts installed from their latest release have no recorded version
+		if version, err := utils.HelmReleaseVersion(pkg.Name, pkg.Namespace); err == nil {
+			installed = version
+		}
+	}
+
+	return change{
+		Component: component,
+		Package:   pkg,
+		Installed: installed,
+		Target:    target,
+	}
+}
+
+// UpToDate reports whether the package already runs the target version.
+// Packages targeting their latest release are always upgraded.
+func (c change) UpToDate() bool {
+	return c.Target != "" && c.Target != "latest" && strings.TrimPrefix(c.Installed, "v") == strings.TrimPrefix(c.Target, "v")
+}
+
+func (c change) Action() string {
+	if c.UpToDate() {
+		return "none, up to date"
+	}
+	if c.Package.Type == "helm" {
+		return "update crds, helm upgrade"
+	}
+	return "apply manifests"
+}
+
+// upgradeChart downloads a chart, updates its custom resource definitions and
+// upgrades the release of the package to it.
+func upgradeChart(pkg types.Package, repoUrl string, version string) (types.Package, error) {
+	dir, err := os.MkdirTemp("", "beamstack-upgrade-")
+	if err != nil {
+		return pkg, err
+	}
+	defer os.RemoveAll(dir)
+
+	chartPath, err := utils.PullHelmChart(pkg.Name, repoUrl, version, dir)
+	if err != nil {
+		return pkg, fmt.Errorf("could not download chart %s: %v", pkg.Name, err)
+	}
+
+	crds, err := utils.HelmChartCRDs(chartPath)
+	if err != nil {
+		return pkg, err
+	}
+	for _, crd := range crds {
+		if err := objects.ApplyManifest(crd); err != nil {
+			return pkg, fmt.Errorf("could not update custom resource definitions of %s: %v", pkg.Name, err)
+		}
+	}
+
+	upgraded, err := utils.UpgradeHelmPackage(pkg.Name, chartPath, version, pkg.Namespace)
+	if err != nil {
+		return pkg, err
+	}
+	upgraded.Url = repoUrl
+	return upgraded, nil
+}
+
+// applyManifest applies a manifest url and keeps a copy of it for uninstall.
+func applyManifest(url string) error {
+	return objects.CreateSavedObject(url, url)
+}
+
+func componentNames() []string {
+	names := []string{}
+	for _, component := range types.Components {
+		if _, ok := upgraders[component.Name]; ok {
+			names = append(names, component.Name)
+		}
+	}
+	return names
+}
+
+func findPackage(profile types.Profiles, name string) (types.Package, bool) {
+	for _, pkg := range profile.Packages {
+		if pkg.Name == name {
+			return pkg, true
+		}
+	}
+	return types.Package{}, false
+}
+
+func replacePackage(profile *types.Profiles, pkg types.Package) {
+	for i := range profile.Packages {
+		if profile.Packages[i].Name == pkg.Name {
+			profile.Packages[i] = pkg
+			return
+		}
+	}
+	profile.Packages = append(profile.Packages, pkg)
+}
+
+func displayVersion(version string) string {
+	if version == "" {
+		return "latest"
+	}
+	return version
+}
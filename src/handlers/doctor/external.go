@@ -0,0 +1,109 @@
+package doctor_handler
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/BeamStackProj/beamstack-cli/src/objects"
+	"github.com/BeamStackProj/beamstack-cli/src/types"
+)
+
+// installSignature identifies an install of a component by a custom resource
+// definition it installs and the image of its controller.
+type installSignature struct {
+	CRD     string
+	Image   string
+	Package string
+}
+
+// installSignatures are the components whose existing installs init can adopt.
+var installSignatures = map[string]installSignature{
+	types.ComponentCertManager:   {CRD: "certificates.cert-manager.io", Image: "cert-manager-controller", Package: "cert-manager"},
+	types.ComponentMonitoring:    {CRD: "prometheuses.monitoring.coreos.com", Image: "prometheus-operator/prometheus-operator", Package: "kube-prometheus-stack"},
+	types.ComponentElasticsearch: {CRD: "elasticsearches.elasticsearch.k8s.elastic.co", Image: "eck/eck-operator", Package: "elasticsearch"},
+}
+
+var crdGVR = schema.GroupVersionResource{
+	Group:    "apiextensions.k8s.io",
+	Version:  "v1",
+	Resource: "customresourcedefinitions",
+}
+
+// DetectInstall looks for an existing install of a component. An install is
+// found when the custom resource definition of the component exists and a
+// deployment runs its controller. An install in namespace is preferred, and
+// an error listing the installs is returned when several are found elsewhere.
+func DetectInstall(clientset kubernetes.Interface, component string, namespace string) (types.ExternalInstall, bool, error) {
+	signature, ok := installSignatures[component]
+	if !ok {
+		return types.ExternalInstall{}, false, nil
+	}
+
+	var crd metav1.PartialObjectMetadata
+	if err := objects.GetDynamicResource(crdGVR, signature.CRD, "", &crd); errors.IsNotFound(err) {
+		return types.ExternalInstall{}, false, nil
+	} else if err != nil {
+		return types.ExternalInstall{}, false, fmt.Errorf("could not look up custom resource definition %s: %v", signature.CRD, err)
+	}
+
+	deployments, err := clientset.AppsV1().Deployments("").List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return types.ExternalInstall{}, false, fmt.Errorf("could not list deployments: %v", err)
+	}
+
+	installs := []types.ExternalInstall{}
+	for _, deployment := range deployments.Items {
+		for _, container := range deployment.Spec.Template.Spec.Containers {
+			if !strings.Contains(container.Image, signature.Image) {
+				continue
+			}
+			install := types.ExternalInstall{
+				Component:  component,
+				Namespace:  deployment.Namespace,
+				Deployment: deployment.Name,
+				Version:    imageTag(container.Image),
+			}
+			if install.Namespace == namespace {
+				return install, true, nil
+			}
+			installs = append(installs, install)
+			break
+		}
+	}
+
+	switch len(installs) {
+	case 0:
+		return types.ExternalInstall{}, false, nil
+	case 1:
+		return installs[0], true, nil
+	}
+	found := make([]string, len(installs))
+	for i, install := range installs {
+		found[i] = fmt.Sprintf("%s/%s", install.Namespace, install.Deployment)
+	}
+	return types.ExternalInstall{}, false, fmt.Errorf("found several %s installs: %s. remove all but one before running init", component, strings.Join(found, ", "))
+}
+
+// ExternalPackage returns the package recorded for an adopted install.
+func ExternalPackage(install types.ExternalInstall) types.Package {
+	return types.Package{
+		Name:      installSignatures[install.Component].Package,
+		Type:      "external",
+		Version:   install.Version,
+		Namespace: install.Namespace,
+	}
+}
+
+func imageTag(image string) string {
+	image, _, _ = strings.Cut(image, "@")
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		return strings.TrimPrefix(image[i+1:], "v")
+	}
+	return ""
+}
@@ -0,0 +1,230 @@
+package doctor_handler
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/version"
+	"k8s.io/client-go/kubernetes"
+
+	info_handler "github.com/BeamStackProj/beamstack-cli/src/handlers/info"
+	"github.com/BeamStackProj/beamstack-cli/src/types"
+	"github.com/BeamStackProj/beamstack-cli/src/utils"
+)
+
+// requiredPermissions are the cluster scoped resources init has to create.
+var requiredPermissions = []authorizationv1.ResourceAttributes{
+	{Group: "apiextensions.k8s.io", Resource: "customresourcedefinitions"},
+	{Group: "", Resource: "namespaces"},
+	{Group: "rbac.authorization.k8s.io", Resource: "clusterroles"},
+	{Group: "rbac.authorization.k8s.io", Resource: "clusterrolebindings"},
+}
+
+// rwxProvisioners are the provisioners known to support ReadWriteMany volumes.
+var rwxProvisioners = []string{"nfs", "cephfs", "efs", "azure-file", "file.csi.azure.com", "filestore", "longhorn", "glusterfs"}
+
+// Preflight checks that the current cluster can run the components.
+func Preflight(components []types.Component) types.PreflightReport {
+	report := types.PreflightReport{}
+
+	clientset, err := kubernetes.NewForConfig(utils.GetKubeConfig())
+	if err != nil {
+		report.Add("cluster access", types.CheckFail, err.Error())
+		return report
+	}
+
+	serverVersion, err := clientset.Discovery().ServerVersion()
+	if err != nil {
+		report.Add("cluster access", types.CheckFail, fmt.Sprintf("could not reach the cluster: %s", err))
+		return report
+	}
+	checkServerVersion(&report, serverVersion.GitVersion, components)
+	checkPermissions(&report, clientset)
+	checkStorageClasses(&report, clientset)
+	checkCapacity(&report, components)
+	checkConflicts(&report, clientset, components)
+
+	return report
+}
+
+// PrintPreflightReport prints the outcome of every check.
+func PrintPreflightReport(report types.PreflightReport) {
+	fmt.Printf("%-36s %-6s %s\n", "CHECK", "STATUS", "DETAILS")
+	for _, check := range report.Checks {
+		fmt.Printf("%-36s %-6s %s\n", check.Name, check.Status, check.Message)
+	}
+}
+
+func checkServerVersion(report *types.PreflightReport, gitVersion string, components []types.Component) {
+	serverVersion, err := version.ParseGeneric(gitVersion)
+	if err != nil {
+		report.Add("kubernetes version", types.CheckWarn, fmt.Sprintf("could not parse server version %s", gitVersion))
+		return
+	}
+
+	unsupported := []string{}
+	for _, component := range components {
+		if component.MinKubeVersion == "" {
+			continue
+		}
+		if !serverVersion.AtLeast(version.MustParseGeneric(component.MinKubeVersion)) {
+			unsupported = append(unsupported, fmt.Sprintf("%s requires %s or later", component.Name, component.MinKubeVersion))
+		}
+	}
+
+	if len(unsupported) > 0 {
+		report.Add("kubernetes version", types.CheckFail, fmt.Sprintf("%s: %s", gitVersion, strings.Join(unsupported, ", ")))
+		return
+	}
+	report.Add("kubernetes version", types.CheckPass, gitVersion)
+}
+
+func checkPermissions(report *types.PreflightReport, clientset kubernetes.Interface) {
+	for _, attributes := range requiredPermissions {
+		attributes.Verb = "create"
+		name := fmt.Sprintf("create %s", attributes.Resource)
+
+		review, err := clientset.AuthorizationV1().SelfSubjectAccessReviews().Create(context.TODO(), &authorizationv1.SelfSubjectAccessReview{
+			Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+				ResourceAttributes: &attributes,
+			},
+		}, metav1.CreateOptions{})
+		if err != nil {
+			report.Add(name, types.CheckWarn, fmt.Sprintf("could not review access: %s", err))
+			continue
+		}
+
+		if !review.Status.Allowed {
+			message := "not allowed for the current user"
+			if review.Status.Reason != "" {
+				message = fmt.Sprintf("%s: %s", message, review.Status.Reason)
+			}
+			report.Add(name, types.CheckFail, message)
+			continue
+		}
+		report.Add(name, types.CheckPass, "allowed")
+	}
+}
+
+func checkStorageClasses(report *types.PreflightReport, clientset kubernetes.Interface) {
+	storageClasses, err := clientset.StorageV1().StorageClasses().List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		report.Add("storage class", types.CheckWarn, fmt.Sprintf("could not list storage classes: %s", err))
+		return
+	}
+	if len(storageClasses.Items) == 0 {
+		report.Add("storage class", types.CheckFail, "cluster has no storage class. flink clusters, elasticsearch and the object store need persistent volumes")
+		return
+	}
+
+	defaultClass := ""
+	rwxClasses := []string{}
+	for _, storageClass := range storageClasses.Items {
+		if storageClass.Annotations["storageclass.kubernetes.io/is-default-class"] == "true" {
+			defaultClass = storageClass.Name
+		}
+		for _, provisioner := range rwxProvisioners {
+			if strings.Contains(storageClass.Provisioner, provisioner) {
+				rwxClasses = append(rwxClasses, storageClass.Name)
+				break
+			}
+		}
+	}
+
+	if defaultClass == "" {
+		report.Add("storage class", types.CheckWarn, "cluster has no default storage class. pass --storage-class when creating resources")
+	} else {
+		report.Add("storage class", types.CheckPass, fmt.Sprintf("default storage class %s", defaultClass))
+	}
+
+	if len(rwxClasses) == 0 {
+		report.Add(fmt.Sprintf("%s storage", v1.ReadWriteMany), types.CheckWarn, "no storage class is known to support ReadWriteMany. create flink clusters with --access-mode ReadWriteOnce")
+	} else {
+		report.Add(fmt.Sprintf("%s storage", v1.ReadWriteMany), types.CheckPass, strings.Join(rwxClasses, ", "))
+	}
+}
+
+func checkCapacity(report *types.PreflightReport, components []types.Component) {
+	pods := []v1.ResourceList{}
+	for _, component := range components {
+		if component.Requests != nil {
+			pods = append(pods, component.Requests)
+		}
+	}
+
+	capacity, err := info_handler.CheckCapacity(pods, types.Scheduling{})
+	if err != nil {
+		report.Add("node capacity", types.CheckWarn, fmt.Sprintf("could not check cluster capacity: %s", err))
+		return
+	}
+
+	requested, free := capacity.Requested, capacity.Free
+	message := fmt.Sprintf("requires cpu %s, memory %s. free cpu %s, memory %s", requested.Cpu(), requested.Memory(), free.Cpu(), free.Memory())
+	if !capacity.Fits() {
+		if capacity.Unplaced > 0 {
+			message = fmt.Sprintf("%s. %d component(s) fit on no single node", message, capacity.Unplaced)
+		}
+		report.Add("node capacity", types.CheckFail, message)
+		return
+	}
+	report.Add("node capacity", types.CheckPass, message)
+}
+
+// checkConflicts looks for existing installs of the components. An install
+// with the workloads init creates, in the namespace init uses, is reused.
+// Any other install is offered for adoption by init.
+func checkConflicts(report *types.PreflightReport, clientset kubernetes.Interface, components []types.Component) {
+	deployments, err := clientset.AppsV1().Deployments("").List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		report.Add("existing installs", types.CheckWarn, fmt.Sprintf("could not list deployments: %s", err))
+		return
+	}
+
+	for _, component := range components {
+		signature, ok := installSignatures[component.Name]
+		if !ok {
+			continue
+		}
+		name := fmt.Sprintf("existing %s", component.Name)
+
+		found := []string{}
+		reused := false
+		for _, deployment := range deployments.Items {
+			for _, container := range deployment.Spec.Template.Spec.Containers {
+				if !strings.Contains(container.Image, signature.Image) {
+					continue
+				}
+				if deployment.Namespace == component.Namespace && isWorkload(component, deployment.Name) {
+					reused = true
+				} else {
+					found = append(found, fmt.Sprintf("%s/%s", deployment.Namespace, deployment.Name))
+				}
+				break
+			}
+		}
+
+		switch {
+		case len(found) > 1 && !reused:
+			report.Add(name, types.CheckWarn, fmt.Sprintf("installed several times as %s. init cannot pick one to adopt", strings.Join(found, ", ")))
+		case len(found) > 0:
+			report.Add(name, types.CheckWarn, fmt.Sprintf("already installed as %s. init offers to adopt it", strings.Join(found, ", ")))
+		case reused:
+			report.Add(name, types.CheckWarn, fmt.Sprintf("already installed in namespace %s and will be reused", component.Namespace))
+		default:
+			report.Add(name, types.CheckPass, "not installed")
+		}
+	}
+}
+
+func isWorkload(component types.Component, name string) bool {
+	for _, workload := range component.Workloads {
+		if workload == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,124 @@
+package flink_handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/BeamStackProj/beamstack-cli/src/types"
+)
+
+// Client is a client for the REST API of a flink cluster. BaseURL is usually a
+// port-forward to the <name>-rest service, but any server speaking the flink
+// REST API works, which keeps the client testable against a local fake.
+type Client struct {
+	BaseURL    string
+	HTTPClient *http.Client
+}
+
+func NewClient(baseURL string) *Client {
+	return &Client{
+		BaseURL:    strings.TrimSuffix(baseURL, "/"),
+		HTTPClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Jobs lists the jobs of the cluster with their state and duration.
+func (c *Client) Jobs() ([]types.FlinkJobOverview, error) {
+	var overview types.FlinkJobsOverview
+	if err := c.do(http.MethodGet, "/jobs/overview", nil, &overview); err != nil {
+		return nil, err
+	}
+	return overview.Jobs, nil
+}
+
+// Job returns a job with the metrics of its vertices.
+func (c *Client) Job(jobID string) (job types.FlinkJobDetails, err error) {
+	err = c.do(http.MethodGet, "/jobs/"+url.PathEscape(jobID), nil, &job)
+	return
+}
+
+// Exceptions returns the exception history of a job.
+func (c *Client) Exceptions(jobID string) (exceptions types.FlinkJobExceptions, err error) {
+	err = c.do(http.MethodGet, "/jobs/"+url.PathEscape(jobID)+"/exceptions", nil, &exceptions)
+	return
+}
+
+// Cancel cancels a running job.
+func (c *Client) Cancel(jobID string) error {
+	return c.do(http.MethodPatch, "/jobs/"+url.PathEscape(jobID)+"?mode=cancel", nil, nil)
+}
+
+// TriggerSavepoint starts an asynchronous savepoint of a job and returns the trigger id
+// to poll with SavepointStatus. An empty target directory uses state.savepoints.dir.
+func (c *Client) TriggerSavepoint(jobID string, request types.FlinkSavepointRequest) (string, error) {
+	var response types.FlinkTriggerResponse
+	if err := c.do(http.MethodPost, "/jobs/"+url.PathEscape(jobID)+"/savepoints", request, &response); err != nil {
+		return "", err
+	}
+	return response.RequestID, nil
+}
+
+func (c *Client) SavepointStatus(jobID string, triggerID string) (status types.FlinkSavepointStatus, err error) {
+	err = c.do(http.MethodGet, "/jobs/"+url.PathEscape(jobID)+"/savepoints/"+url.PathEscape(triggerID), nil, &status)
+	return
+}
+
+// Config returns the configuration of the job manager.
+func (c *Client) Config() (config []types.FlinkConfigEntry, err error) {
+	err = c.do(http.MethodGet, "/jobmanager/config", nil, &config)
+	return
+}
+
+func (c *Client) do(method string, path string, body interface{}, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("error marshalling request: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, c.BaseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode >= 300 {
+		var apiError struct {
+			Errors []string `json:"errors"`
+		}
+		if json.Unmarshal(data, &apiError) == nil && len(apiError.Errors) > 0 {
+			return fmt.Errorf("flink %s %s: %s", method, path, strings.Join(apiError.Errors, "; "))
+		}
+		return fmt.Errorf("flink %s %s: %s", method, path, resp.Status)
+	}
+
+	if out == nil || len(data) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("error parsing flink response: %w", err)
+	}
+	return nil
+}
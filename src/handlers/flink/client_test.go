@@ -0,0 +1,183 @@
+package flink_handler
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/BeamStackProj/beamstack-cli/src/types"
+)
+
+// fakeFlink serves canned responses of the flink REST API, keyed by method
+// and request uri, and records the bodies it receives.
+type fakeFlink struct {
+	responses map[string]string
+	bodies    map[string]string
+}
+
+func newFakeFlink(t *testing.T, responses map[string]string) (*fakeFlink, *Client) {
+	fake := &fakeFlink{responses: responses, bodies: map[string]string{}}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+	return fake, NewClient(server.URL + "/")
+}
+
+func (f *fakeFlink) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	key := r.Method + " " + r.URL.RequestURI()
+	body, _ := io.ReadAll(r.Body)
+	f.bodies[key] = string(body)
+
+	response, ok := f.responses[key]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"errors":["Not found: ` + r.URL.Path + `"]}`))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(response))
+}
+
+func TestJobs(t *testing.T) {
+	_, client := newFakeFlink(t, map[string]string{
+		"GET /jobs/overview": `{"jobs":[{"jid":"a1","name":"wordcount","state":"RUNNING","start-time":1000,"end-time":-1,"duration":5000}]}`,
+	})
+
+	jobs, err := client.Jobs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(jobs) != 1 {
+		t.Fatalf("got %d jobs, want 1", len(jobs))
+	}
+	if jobs[0].ID != "a1" || jobs[0].Name != "wordcount" || jobs[0].State != "RUNNING" || jobs[0].Duration != 5000 {
+		t.Errorf("unexpected job %+v", jobs[0])
+	}
+}
+
+func TestJob(t *testing.T) {
+	_, client := newFakeFlink(t, map[string]string{
+		"GET /jobs/a1": `{"jid":"a1","name":"wordcount","state":"RUNNING","duration":5000,"vertices":[{"id":"v1","name":"Source","parallelism":2,"status":"RUNNING","duration":4000,"metrics":{"read-records":10,"write-records":20}}]}`,
+	})
+
+	job, err := client.Job("a1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(job.Vertices) != 1 {
+		t.Fatalf("got %d vertices, want 1", len(job.Vertices))
+	}
+	vertex := job.Vertices[0]
+	if vertex.Parallelism != 2 || vertex.Metrics.ReadRecords != 10 || vertex.Metrics.WriteRecords != 20 {
+		t.Errorf("unexpected vertex %+v", vertex)
+	}
+}
+
+func TestExceptions(t *testing.T) {
+	_, client := newFakeFlink(t, map[string]string{
+		"GET /jobs/a1/exceptions": `{"exceptionHistory":{"entries":[{"exceptionName":"java.lang.RuntimeException","stacktrace":"boom","timestamp":1000,"taskName":"Map"}],"truncated":false}}`,
+	})
+
+	exceptions, err := client.Exceptions("a1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries := exceptions.History.Entries
+	if len(entries) != 1 {
+		t.Fatalf("got %d exceptions, want 1", len(entries))
+	}
+	if entries[0].Name != "java.lang.RuntimeException" || entries[0].TaskName == nil || *entries[0].TaskName != "Map" {
+		t.Errorf("unexpected exception %+v", entries[0])
+	}
+}
+
+func TestCancel(t *testing.T) {
+	fake, client := newFakeFlink(t, map[string]string{
+		"PATCH /jobs/a1?mode=cancel": ``,
+	})
+
+	if err := client.Cancel("a1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := fake.bodies["PATCH /jobs/a1?mode=cancel"]; !ok {
+		t.Error("cancel request was not sent")
+	}
+}
+
+func TestTriggerSavepoint(t *testing.T) {
+	fake, client := newFakeFlink(t, map[string]string{
+		"POST /jobs/a1/savepoints": `{"request-id":"t1"}`,
+	})
+
+	triggerID, err := client.TriggerSavepoint("a1", types.FlinkSavepointRequest{TargetDirectory: "file:///pvc/savepoints", CancelJob: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if triggerID != "t1" {
+		t.Errorf("got trigger id %q, want t1", triggerID)
+	}
+
+	var request types.FlinkSavepointRequest
+	if err := json.Unmarshal([]byte(fake.bodies["POST /jobs/a1/savepoints"]), &request); err != nil {
+		t.Fatal(err)
+	}
+	if request.TargetDirectory != "file:///pvc/savepoints" || !request.CancelJob {
+		t.Errorf("unexpected savepoint request %+v", request)
+	}
+}
+
+func TestSavepointStatus(t *testing.T) {
+	_, client := newFakeFlink(t, map[string]string{
+		"GET /jobs/a1/savepoints/t1": `{"status":{"id":"COMPLETED"},"operation":{"location":"file:///pvc/savepoints/savepoint-a1"}}`,
+	})
+
+	status, err := client.SavepointStatus("a1", "t1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Status.ID != "COMPLETED" {
+		t.Errorf("got status %q, want COMPLETED", status.Status.ID)
+	}
+	if status.Operation == nil || status.Operation.Location != "file:///pvc/savepoints/savepoint-a1" {
+		t.Errorf("unexpected operation %+v", status.Operation)
+	}
+}
+
+func TestConfig(t *testing.T) {
+	_, client := newFakeFlink(t, map[string]string{
+		"GET /jobmanager/config": `[{"key":"state.savepoints.dir","value":"file:///pvc/savepoints"}]`,
+	})
+
+	config, err := client.Config()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(config) != 1 || config[0].Key != "state.savepoints.dir" || config[0].Value != "file:///pvc/savepoints" {
+		t.Errorf("unexpected config %+v", config)
+	}
+}
+
+func TestErrorResponse(t *testing.T) {
+	_, client := newFakeFlink(t, map[string]string{})
+
+	_, err := client.Job("missing")
+	if err == nil {
+		t.Fatal("expected an error for a missing job")
+	}
+	if !strings.Contains(err.Error(), "Not found: /jobs/missing") {
+		t.Errorf("error %q does not carry the flink error message", err)
+	}
+}
+
+func TestErrorStatusWithoutBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	t.Cleanup(server.Close)
+
+	if err := NewClient(server.URL).Cancel("a1"); err == nil || !strings.Contains(err.Error(), "500") {
+		t.Errorf("expected an error with the response status, got %v", err)
+	}
+}
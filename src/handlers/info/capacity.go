@@ -0,0 +1,280 @@
+package info_handler
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/BeamStackProj/beamstack-cli/src/types"
+	"github.com/BeamStackProj/beamstack-cli/src/utils"
+)
+
+var capacityResources = []v1.ResourceName{v1.ResourceCPU, v1.ResourceMemory}
+
+// Capacity computes, for every ready and schedulable node, the allocatable
+// resources and the resources already requested by the pods running on it.
+func Capacity() (types.ClusterCapacity, error) {
+	clusterHealth, err := Health()
+	if err != nil {
+		return types.ClusterCapacity{}, err
+	}
+
+	clientset, err := kubernetes.NewForConfig(utils.GetKubeConfig())
+	if err != nil {
+		return types.ClusterCapacity{}, err
+	}
+
+	pods, err := clientset.CoreV1().Pods("").List(context.TODO(), metav1.ListOptions{
+		FieldSelector: "status.phase!=Succeeded,status.phase!=Failed",
+	})
+	if err != nil {
+		return types.ClusterCapacity{}, err
+	}
+
+	requested := map[string]v1.ResourceList{}
+	for _, pod := range pods.Items {
+		if pod.Spec.NodeName == "" {
+			continue
+		}
+		if _, ok := requested[pod.Spec.NodeName]; !ok {
+			requested[pod.Spec.NodeName] = v1.ResourceList{}
+		}
+		addResources(requested[pod.Spec.NodeName], podRequests(pod.Spec))
+	}
+
+	capacity := types.ClusterCapacity{}
+	for _, node := range clusterHealth.Nodes {
+		if node.Spec.Unschedulable || !nodeReady(node) {
+			continue
+		}
+		capacity.Nodes = append(capacity.Nodes, types.NodeCapacity{
+			Node:        node,
+			Allocatable: node.Status.Allocatable,
+			Requested:   requested[node.Name],
+		})
+	}
+
+	return capacity, nil
+}
+
+// CheckCapacity checks whether pods with the given resource requests can be
+// scheduled on the nodes that match the scheduling constraints. Pods are
+// placed largest first onto the free resources of each node.
+func CheckCapacity(pods []v1.ResourceList, scheduling types.Scheduling) (types.CapacityReport, error) {
+	capacity, err := Capacity()
+	if err != nil {
+		return types.CapacityReport{}, err
+	}
+	return placePods(capacity, pods, scheduling), nil
+}
+
+// placePods places the pods first fit, largest first, onto the free
+// resources of the nodes of the cluster that match the scheduling constraints.
+func placePods(capacity types.ClusterCapacity, pods []v1.ResourceList, scheduling types.Scheduling) types.CapacityReport {
+	report := types.CapacityReport{
+		Requested: v1.ResourceList{},
+		Free:      v1.ResourceList{},
+		Shortfall: v1.ResourceList{},
+	}
+
+	free := []v1.ResourceList{}
+	for _, node := range capacity.Nodes {
+		if !matchesScheduling(node.Node, scheduling) {
+			continue
+		}
+		nodeFree := node.Free()
+		free = append(free, nodeFree)
+		addResources(report.Free, nodeFree)
+	}
+
+	sorted := append([]v1.ResourceList{}, pods...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Cpu().Cmp(*sorted[j].Cpu()) > 0
+	})
+
+	for _, pod := range sorted {
+		addResources(report.Requested, pod)
+
+		placed := false
+		for _, nodeFree := range free {
+			if fits(pod, nodeFree) {
+				subtractResources(nodeFree, pod)
+				placed = true
+				break
+			}
+		}
+		if !placed {
+			report.Unplaced++
+		}
+	}
+
+	for _, name := range capacityResources {
+		requested, ok := report.Requested[name]
+		if !ok {
+			continue
+		}
+		available := report.Free[name]
+		if requested.Cmp(available) > 0 {
+			shortfall := requested.DeepCopy()
+			shortfall.Sub(available)
+			report.Shortfall[name] = shortfall
+		}
+	}
+
+	return report
+}
+
+// PrintCapacityReport prints the requested and free resources and the shortfall per resource.
+func PrintCapacityReport(report types.CapacityReport) {
+	fmt.Printf("  %-10s %-12s %-12s %s\n", "RESOURCE", "REQUESTED", "FREE", "SHORTFALL")
+	for _, name := range capacityResources {
+		requested := report.Requested[name]
+		free := report.Free[name]
+		shortfall := "-"
+		if quantity, ok := report.Shortfall[name]; ok {
+			shortfall = quantity.String()
+		}
+		fmt.Printf("  %-10s %-12s %-12s %s\n", name, requested.String(), free.String(), shortfall)
+	}
+	if report.Unplaced > 0 {
+		fmt.Printf("  %d pod(s) are larger than the free resources of any single node\n", report.Unplaced)
+	}
+}
+
+func podRequests(spec v1.PodSpec) v1.ResourceList {
+	requests := v1.ResourceList{}
+	for _, container := range spec.Containers {
+		addResources(requests, container.Resources.Requests)
+	}
+	for _, container := range spec.InitContainers {
+		for name, quantity := range container.Resources.Requests {
+			if current, ok := requests[name]; !ok || quantity.Cmp(current) > 0 {
+				requests[name] = quantity.DeepCopy()
+			}
+		}
+	}
+	addResources(requests, spec.Overhead)
+	return requests
+}
+
+func nodeReady(node v1.Node) bool {
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == v1.NodeReady {
+			return condition.Status == v1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// matchesScheduling reports whether pods with the scheduling constraints can
+// be scheduled on a node, from its labels and taints. Preferred affinity and
+// pod affinity depend on other pods and are not considered.
+func matchesScheduling(node v1.Node, scheduling types.Scheduling) bool {
+	for key, value := range scheduling.NodeSelector {
+		if node.Labels[key] != value {
+			return false
+		}
+	}
+
+	if affinity := scheduling.Affinity; affinity != nil && affinity.NodeAffinity != nil {
+		if required := affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution; required != nil && !matchesNodeSelector(node, *required) {
+			return false
+		}
+	}
+
+	for i := range node.Spec.Taints {
+		taint := &node.Spec.Taints[i]
+		if taint.Effect == v1.TaintEffectPreferNoSchedule {
+			continue
+		}
+		tolerated := false
+		for j := range scheduling.Tolerations {
+			if scheduling.Tolerations[j].ToleratesTaint(taint) {
+				tolerated = true
+				break
+			}
+		}
+		if !tolerated {
+			return false
+		}
+	}
+	return true
+}
+
+// nodeSelectorOperators maps the operators of node selector requirements to
+// the operators of label selectors.
+var nodeSelectorOperators = map[v1.NodeSelectorOperator]selection.Operator{
+	v1.NodeSelectorOpIn:           selection.In,
+	v1.NodeSelectorOpNotIn:        selection.NotIn,
+	v1.NodeSelectorOpExists:       selection.Exists,
+	v1.NodeSelectorOpDoesNotExist: selection.DoesNotExist,
+	v1.NodeSelectorOpGt:           selection.GreaterThan,
+	v1.NodeSelectorOpLt:           selection.LessThan,
+}
+
+// matchesNodeSelector reports whether a node matches one of the terms of a
+// node selector, as the scheduler evaluates required node affinity. A term
+// matches when all its requirements match, and a term without requirements
+// or with an invalid requirement matches no node.
+func matchesNodeSelector(node v1.Node, selector v1.NodeSelector) bool {
+	nodeFields := labels.Set{"metadata.name": node.Name}
+	for _, term := range selector.NodeSelectorTerms {
+		if len(term.MatchExpressions) == 0 && len(term.MatchFields) == 0 {
+			continue
+		}
+		if matchesRequirements(labels.Set(node.Labels), term.MatchExpressions) && matchesRequirements(nodeFields, term.MatchFields) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesRequirements(set labels.Set, requirements []v1.NodeSelectorRequirement) bool {
+	for _, requirement := range requirements {
+		operator, ok := nodeSelectorOperators[requirement.Operator]
+		if !ok {
+			return false
+		}
+		selector, err := labels.NewRequirement(requirement.Key, operator, requirement.Values)
+		if err != nil || !selector.Matches(set) {
+			return false
+		}
+	}
+	return true
+}
+
+func fits(pod v1.ResourceList, free v1.ResourceList) bool {
+	for name, quantity := range pod {
+		available, ok := free[name]
+		if !ok || quantity.Cmp(available) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func addResources(total v1.ResourceList, add v1.ResourceList) {
+	for name, quantity := range add {
+		if current, ok := total[name]; ok {
+			current.Add(quantity)
+			total[name] = current
+		} else {
+			total[name] = quantity.DeepCopy()
+		}
+	}
+}
+
+func subtractResources(total v1.ResourceList, sub v1.ResourceList) {
+	for name, quantity := range sub {
+		if current, ok := total[name]; ok {
+			current.Sub(quantity)
+			total[name] = current
+		}
+	}
+}
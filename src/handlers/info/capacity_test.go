@@ -0,0 +1,204 @@
+package info_handler
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/BeamStackProj/beamstack-cli/src/types"
+)
+
+func resources(cpu string, memory string) v1.ResourceList {
+	return v1.ResourceList{
+		v1.ResourceCPU:    resource.MustParse(cpu),
+		v1.ResourceMemory: resource.MustParse(memory),
+	}
+}
+
+func requiredAffinity(terms ...v1.NodeSelectorTerm) types.Scheduling {
+	return types.Scheduling{
+		Affinity: &v1.Affinity{
+			NodeAffinity: &v1.NodeAffinity{
+				RequiredDuringSchedulingIgnoredDuringExecution: &v1.NodeSelector{NodeSelectorTerms: terms},
+			},
+		},
+	}
+}
+
+func expressions(requirements ...v1.NodeSelectorRequirement) v1.NodeSelectorTerm {
+	return v1.NodeSelectorTerm{MatchExpressions: requirements}
+}
+
+func requirement(key string, operator v1.NodeSelectorOperator, values ...string) v1.NodeSelectorRequirement {
+	return v1.NodeSelectorRequirement{Key: key, Operator: operator, Values: values}
+}
+
+func TestMatchesScheduling(t *testing.T) {
+	node := v1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "node-1",
+			Labels: map[string]string{"pool": "beam", "cpus": "8"},
+		},
+	}
+	tainted := *node.DeepCopy()
+	tainted.Spec.Taints = []v1.Taint{{Key: "dedicated", Value: "beam", Effect: v1.TaintEffectNoSchedule}}
+	preferred := *node.DeepCopy()
+	preferred.Spec.Taints = []v1.Taint{{Key: "dedicated", Value: "beam", Effect: v1.TaintEffectPreferNoSchedule}}
+
+	tests := []struct {
+		name       string
+		node       v1.Node
+		scheduling types.Scheduling
+		want       bool
+	}{
+		{"no constraints", node, types.Scheduling{}, true},
+		{"node selector match", node, types.Scheduling{NodeSelector: map[string]string{"pool": "beam"}}, true},
+		{"node selector mismatch", node, types.Scheduling{NodeSelector: map[string]string{"pool": "batch"}}, false},
+		{"in", node, requiredAffinity(expressions(requirement("pool", v1.NodeSelectorOpIn, "batch", "beam"))), true},
+		{"in mismatch", node, requiredAffinity(expressions(requirement("pool", v1.NodeSelectorOpIn, "batch"))), false},
+		{"not in", node, requiredAffinity(expressions(requirement("pool", v1.NodeSelectorOpNotIn, "batch"))), true},
+		{"not in mismatch", node, requiredAffinity(expressions(requirement("pool", v1.NodeSelectorOpNotIn, "beam"))), false},
+		{"exists", node, requiredAffinity(expressions(requirement("pool", v1.NodeSelectorOpExists))), true},
+		{"exists mismatch", node, requiredAffinity(expressions(requirement("gpu", v1.NodeSelectorOpExists))), false},
+		{"does not exist", node, requiredAffinity(expressions(requirement("gpu", v1.NodeSelectorOpDoesNotExist))), true},
+		{"does not exist mismatch", node, requiredAffinity(expressions(requirement("pool", v1.NodeSelectorOpDoesNotExist))), false},
+		{"greater than", node, requiredAffinity(expressions(requirement("cpus", v1.NodeSelectorOpGt, "4"))), true},
+		{"less than mismatch", node, requiredAffinity(expressions(requirement("cpus", v1.NodeSelectorOpLt, "4"))), false},
+		{
+			"requirements of a term are all required", node,
+			requiredAffinity(expressions(requirement("pool", v1.NodeSelectorOpIn, "beam"), requirement("gpu", v1.NodeSelectorOpExists))),
+			false,
+		},
+		{
+			"one matching term is enough", node,
+			requiredAffinity(expressions(requirement("gpu", v1.NodeSelectorOpExists)), expressions(requirement("pool", v1.NodeSelectorOpIn, "beam"))),
+			true,
+		},
+		{"no terms", node, requiredAffinity(), false},
+		{"empty term", node, requiredAffinity(v1.NodeSelectorTerm{}), false},
+		{
+			"match fields", node,
+			requiredAffinity(v1.NodeSelectorTerm{MatchFields: []v1.NodeSelectorRequirement{requirement("metadata.name", v1.NodeSelectorOpIn, "node-1")}}),
+			true,
+		},
+		{"no schedule taint", tainted, types.Scheduling{}, false},
+		{
+			"no schedule taint tolerated", tainted,
+			types.Scheduling{Tolerations: []v1.Toleration{{Key: "dedicated", Operator: v1.TolerationOpEqual, Value: "beam", Effect: v1.TaintEffectNoSchedule}}},
+			true,
+		},
+		{
+			"no schedule taint tolerated by another value", tainted,
+			types.Scheduling{Tolerations: []v1.Toleration{{Key: "dedicated", Operator: v1.TolerationOpEqual, Value: "batch", Effect: v1.TaintEffectNoSchedule}}},
+			false,
+		},
+		{"prefer no schedule taint", preferred, types.Scheduling{}, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := matchesScheduling(test.node, test.scheduling); got != test.want {
+				t.Errorf("got %t, want %t", got, test.want)
+			}
+		})
+	}
+}
+
+func TestPlacePods(t *testing.T) {
+	nodeCapacity := func(name string, pool string, free v1.ResourceList) types.NodeCapacity {
+		return types.NodeCapacity{
+			Node: v1.Node{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{"pool": pool}},
+			},
+			Allocatable: free,
+		}
+	}
+	cluster := types.ClusterCapacity{
+		Nodes: []types.NodeCapacity{
+			nodeCapacity("node-1", "beam", resources("2", "4Gi")),
+			nodeCapacity("node-2", "beam", resources("2", "4Gi")),
+			nodeCapacity("node-3", "batch", resources("8", "16Gi")),
+		},
+	}
+	beamPool := types.Scheduling{NodeSelector: map[string]string{"pool": "beam"}}
+
+	tests := []struct {
+		name       string
+		pods       []v1.ResourceList
+		scheduling types.Scheduling
+		unplaced   int
+		shortfall  v1.ResourceList
+	}{
+		{
+			name:       "pods fit",
+			pods:       []v1.ResourceList{resources("2", "2Gi"), resources("1", "2Gi"), resources("1", "2Gi")},
+			scheduling: beamPool,
+		},
+		{
+			name:       "pod fits the cluster total but no single node",
+			pods:       []v1.ResourceList{resources("3", "2Gi")},
+			scheduling: beamPool,
+			unplaced:   1,
+		},
+		{
+			name:       "pods exceed the matching nodes",
+			pods:       []v1.ResourceList{resources("2", "4Gi"), resources("2", "4Gi"), resources("1", "1Gi")},
+			scheduling: beamPool,
+			unplaced:   1,
+			shortfall:  resources("1", "1Gi"),
+		},
+		{
+			name: "every node is used without constraints",
+			pods: []v1.ResourceList{resources("6", "8Gi"), resources("2", "4Gi")},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			report := placePods(cluster, test.pods, test.scheduling)
+			if report.Unplaced != test.unplaced {
+				t.Errorf("got %d unplaced pods, want %d", report.Unplaced, test.unplaced)
+			}
+			if len(report.Shortfall) != len(test.shortfall) {
+				t.Fatalf("got shortfall %v, want %v", report.Shortfall, test.shortfall)
+			}
+			for name, want := range test.shortfall {
+				if got := report.Shortfall[name]; got.Cmp(want) != 0 {
+					t.Errorf("got %s shortfall %s, want %s", name, got.String(), want.String())
+				}
+			}
+			if report.Fits() != (test.unplaced == 0 && len(test.shortfall) == 0) {
+				t.Errorf("got fits %t", report.Fits())
+			}
+		})
+	}
+}
+
+func TestPlacePodsCountsMatchingNodesOnly(t *testing.T) {
+	cluster := types.ClusterCapacity{
+		Nodes: []types.NodeCapacity{
+			{
+				Node:        v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}},
+				Allocatable: resources("4", "8Gi"),
+				Requested:   resources("1", "2Gi"),
+			},
+			{
+				Node: v1.Node{
+					ObjectMeta: metav1.ObjectMeta{Name: "node-2"},
+					Spec:       v1.NodeSpec{Taints: []v1.Taint{{Key: "dedicated", Effect: v1.TaintEffectNoSchedule}}},
+				},
+				Allocatable: resources("4", "8Gi"),
+			},
+		},
+	}
+
+	report := placePods(cluster, nil, types.Scheduling{})
+	if free := report.Free[v1.ResourceCPU]; free.Cmp(resource.MustParse("3")) != 0 {
+		t.Errorf("got %s free cpu, want 3", free.String())
+	}
+	if free := report.Free[v1.ResourceMemory]; free.Cmp(resource.MustParse("6Gi")) != 0 {
+		t.Errorf("got %s free memory, want 6Gi", free.String())
+	}
+}
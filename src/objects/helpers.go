@@ -17,27 +17,25 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
-	"k8s.io/apimachinery/pkg/util/strategicpatch"
 	"k8s.io/apimachinery/pkg/util/yaml"
 	"k8s.io/client-go/dynamic"
 )
 
-func applyYAML(dynamicClient dynamic.Interface, path string) error {
-	var data []byte
-	var err error
+// fieldManager owns the fields beamstack sets on the objects it applies.
+const fieldManager = "beamstack"
 
-	if isURL(path) {
-		data, err = downloadFile(path)
-		if err != nil {
-			return err
-		}
-	} else {
-		data, err = os.ReadFile(path)
-		if err != nil {
-			return err
-		}
+func applyYAML(dynamicClient dynamic.Interface, path string) error {
+	data, err := readManifest(path)
+	if err != nil {
+		return err
 	}
+	return applyManifest(dynamicClient, data)
+}
 
+// applyManifest creates the objects of a manifest, and updates the ones that
+// already exist with a server side apply, so manifests of a newer release
+// can be applied over an older one.
+func applyManifest(dynamicClient dynamic.Interface, data []byte) error {
 	yamlDecoder := yaml.NewYAMLOrJSONDecoder(bytes.NewReader(data), 100)
 	for {
 		var rawObj runtime.RawExtension
@@ -47,6 +45,9 @@ func applyYAML(dynamicClient dynamic.Interface, path string) error {
 			}
 			return err
 		}
+		if len(rawObj.Raw) == 0 {
+			continue
+		}
 
 		obj := &unstructured.Unstructured{}
 		if err := json.Unmarshal(rawObj.Raw, obj); err != nil {
@@ -62,44 +63,88 @@ func applyYAML(dynamicClient dynamic.Interface, path string) error {
 			},
 		).Namespace(obj.GetNamespace())
 
-		existing, err := resourceClient.Get(context.Background(), obj.GetName(), metav1.GetOptions{})
+		_, err := resourceClient.Get(context.Background(), obj.GetName(), metav1.GetOptions{})
 
 		if errors.IsNotFound(err) {
 			// Create the resource
-			_, err = resourceClient.Create(context.Background(), obj, metav1.CreateOptions{})
+			_, err = resourceClient.Create(context.Background(), obj, metav1.CreateOptions{FieldManager: fieldManager})
 			if err != nil {
 				return err
 			}
-			// fmt.Printf("Created %s %s/%s\n", gvk.Kind, obj.GetNamespace(), obj.GetName())
 		} else if err != nil {
 			return err
 		} else {
-			return nil //TODO: fix patch bug
-			// Update the resource
-			existingJSON, err := json.Marshal(existing.Object)
+			// Update the resource, taking over the fields the manifest sets
+			force := true
+			_, err = resourceClient.Patch(context.Background(), obj.GetName(), types.ApplyPatchType, rawObj.Raw, metav1.PatchOptions{
+				FieldManager: fieldManager,
+				Force:        &force,
+			})
 			if err != nil {
-				return err
+				return fmt.Errorf("could not update %s %s: %v", gvk.Kind, obj.GetName(), err)
 			}
+		}
+	}
 
-			rawObjJSON, err := json.Marshal(rawObj.Object)
-			if err != nil {
-				return err
-			}
-			patch, err := strategicpatch.CreateTwoWayMergePatch(existingJSON, rawObjJSON, obj)
-			if err != nil {
-				return err
-			}
-			_, err = resourceClient.Patch(context.Background(), obj.GetName(), types.StrategicMergePatchType, patch, metav1.PatchOptions{})
-			if err != nil {
-				return err
+	return nil
+}
+
+// deleteYAML deletes the objects of a manifest in reverse order, so namespaces
+// are deleted after the objects they hold. Custom resource definitions are
+// only deleted when crds is set. Objects that no longer exist are skipped.
+func deleteYAML(dynamicClient dynamic.Interface, data []byte, crds bool) error {
+	objs := []*unstructured.Unstructured{}
+
+	yamlDecoder := yaml.NewYAMLOrJSONDecoder(bytes.NewReader(data), 100)
+	for {
+		var rawObj runtime.RawExtension
+		if err := yamlDecoder.Decode(&rawObj); err != nil {
+			if err == io.EOF {
+				break
 			}
-			fmt.Printf("Updated %s %s/%s\n", gvk.Kind, obj.GetNamespace(), obj.GetName())
+			return err
+		}
+		if len(rawObj.Raw) == 0 {
+			continue
+		}
+
+		obj := &unstructured.Unstructured{}
+		if err := json.Unmarshal(rawObj.Raw, obj); err != nil {
+			return err
+		}
+		if obj.GetKind() == "CustomResourceDefinition" && !crds {
+			continue
+		}
+		objs = append(objs, obj)
+	}
+
+	propagation := metav1.DeletePropagationBackground
+	for i := len(objs) - 1; i >= 0; i-- {
+		gvk := objs[i].GroupVersionKind()
+		resourceClient := dynamicClient.Resource(
+			schema.GroupVersionResource{
+				Group:    gvk.Group,
+				Version:  gvk.Version,
+				Resource: strings.ToLower(gvk.Kind) + "s",
+			},
+		).Namespace(objs[i].GetNamespace())
+
+		err := resourceClient.Delete(context.Background(), objs[i].GetName(), metav1.DeleteOptions{PropagationPolicy: &propagation})
+		if err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("could not delete %s %s: %v", gvk.Kind, objs[i].GetName(), err)
 		}
 	}
 
 	return nil
 }
 
+func readManifest(path string) ([]byte, error) {
+	if isURL(path) {
+		return downloadFile(path)
+	}
+	return os.ReadFile(path)
+}
+
 func isURL(str string) bool {
 	u, err := url.Parse(str)
 	return err == nil && u.Scheme != "" && u.Host != ""
@@ -111,6 +156,9 @@ func downloadFile(url string) ([]byte, error) {
 		return nil, err
 	}
 	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("downloading %s: %s", url, resp.Status)
+	}
 	return io.ReadAll(resp.Body)
 }
 
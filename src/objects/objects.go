@@ -8,13 +8,20 @@ import (
 
 	batchv1 "k8s.io/api/batch/v1"
 	v1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	storagev1 "k8s.io/api/storage/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 
+	"github.com/BeamStackProj/beamstack-cli/src/types"
 	"github.com/BeamStackProj/beamstack-cli/src/utils"
 )
 
@@ -40,6 +47,53 @@ func CreateObject(path string) error {
 	return nil
 }
 
+// CreateSavedObject applies a manifest file or url like CreateObject, and
+// keeps a copy of it under the source it was installed from. Path differs from
+// source when the manifest is installed from a bundle.
+func CreateSavedObject(source string, path string) error {
+	data, err := readManifest(path)
+	if err != nil {
+		return fmt.Errorf("error reading manifest %s: %v", path, err)
+	}
+	if err := ApplyManifest(data); err != nil {
+		return fmt.Errorf("error applying yaml from url %v", err)
+	}
+	return utils.SaveManifest(source, data)
+}
+
+// ApplyManifest creates or updates the objects of a manifest.
+func ApplyManifest(data []byte) error {
+	dynamicClient, err := dynamic.NewForConfig(utils.GetKubeConfig())
+	if err != nil {
+		return err
+	}
+	return applyManifest(dynamicClient, data)
+}
+
+// DeleteObject deletes the objects of a manifest file or url. Custom resource
+// definitions are only deleted when crds is set. The copy kept when the
+// manifest was applied with CreateSavedObject is used if there is one.
+func DeleteObject(path string, crds bool) error {
+	if saved, ok := utils.SavedManifest(path); ok {
+		path = saved
+	}
+	data, err := readManifest(path)
+	if err != nil {
+		return fmt.Errorf("error reading manifest %s: %v", path, err)
+	}
+	return DeleteManifest(data, crds)
+}
+
+// DeleteManifest deletes the objects of a manifest. Custom resource
+// definitions are only deleted when crds is set.
+func DeleteManifest(data []byte, crds bool) error {
+	dynamicClient, err := dynamic.NewForConfig(utils.GetKubeConfig())
+	if err != nil {
+		return err
+	}
+	return deleteYAML(dynamicClient, data, crds)
+}
+
 func CreateNamespace(name string) error {
 	config := utils.GetKubeConfig()
 
@@ -85,6 +139,52 @@ func CreateSecret(name string, namespace string, data map[string][]byte) error {
 	return nil
 }
 
+// ApplySecret creates a secret, or replaces the data of the secret if it exists.
+func ApplySecret(name string, namespace string, data map[string][]byte) error {
+	clientset, err := kubernetes.NewForConfig(utils.GetKubeConfig())
+	if err != nil {
+		return err
+	}
+
+	existing, err := clientset.CoreV1().Secrets(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return CreateSecret(name, namespace, data)
+	} else if err != nil {
+		return err
+	}
+
+	existing.Data = data
+	_, err = clientset.CoreV1().Secrets(namespace).Update(context.TODO(), existing, metav1.UpdateOptions{})
+	return err
+}
+
+// CopySecret copies the data of a secret into another namespace, replacing
+// the data of an earlier copy.
+func CopySecret(clientset *kubernetes.Clientset, name string, namespace string, targetName string, targetNamespace string) error {
+	secret, err := clientset.CoreV1().Secrets(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+
+	existing, err := clientset.CoreV1().Secrets(targetNamespace).Get(context.TODO(), targetName, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		_, err = clientset.CoreV1().Secrets(targetNamespace).Create(context.TODO(), &v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      targetName,
+				Namespace: targetNamespace,
+			},
+			Data: secret.Data,
+		}, metav1.CreateOptions{})
+		return err
+	} else if err != nil {
+		return err
+	}
+
+	existing.Data = secret.Data
+	_, err = clientset.CoreV1().Secrets(targetNamespace).Update(context.TODO(), existing, metav1.UpdateOptions{})
+	return err
+}
+
 func CreateDynamicResource(typeMeta metav1.TypeMeta, metaData metav1.ObjectMeta, specs interface{}, resourcetype string) error {
 	config := utils.GetKubeConfig()
 
@@ -126,7 +226,65 @@ func CreateDynamicResource(typeMeta metav1.TypeMeta, metaData metav1.ObjectMeta,
 	return nil
 }
 
-func CreatePVC(clientset *kubernetes.Clientset, name string, namespace string, size string) error {
+func GetDynamicResource(gvr schema.GroupVersionResource, name string, namespace string, out interface{}) error {
+	config := utils.GetKubeConfig()
+
+	client, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+
+	obj, err := client.Resource(gvr).Namespace(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+
+	return runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, out)
+}
+
+// GetFlinkCluster returns a flink cluster, refusing clusters of another
+// environment than the one of the profile.
+func GetFlinkCluster(profile types.Profiles, name string, namespace string) (types.FlinkDeployment, error) {
+	var cluster types.FlinkDeployment
+	if err := GetDynamicResource(types.FlinkDeploymentGVR, name, namespace, &cluster); err != nil {
+		return cluster, err
+	}
+	if !profile.Owns(cluster.Labels) {
+		return cluster, fmt.Errorf("flink cluster %s/%s belongs to environment %s", namespace, name, cluster.Labels[types.ProfileEnvironmentLabel])
+	}
+	return cluster, nil
+}
+
+// GetElasticsearch returns an elasticsearch cluster, refusing clusters of
+// another environment than the one of the profile.
+func GetElasticsearch(profile types.Profiles, name string, namespace string) (types.Elasticsearch, error) {
+	var cluster types.Elasticsearch
+	if err := GetDynamicResource(types.EsGVR, name, namespace, &cluster); err != nil {
+		return cluster, err
+	}
+	if !profile.Owns(cluster.Labels) {
+		return cluster, fmt.Errorf("elasticsearch cluster %s/%s belongs to environment %s", namespace, name, cluster.Labels[types.ProfileEnvironmentLabel])
+	}
+	return cluster, nil
+}
+
+func ListDynamicResources(gvr schema.GroupVersionResource, namespace string) ([]unstructured.Unstructured, error) {
+	config := utils.GetKubeConfig()
+
+	client, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := client.Resource(gvr).Namespace(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	return list.Items, nil
+}
+
+func CreatePVC(clientset *kubernetes.Clientset, name string, namespace string, size string, storageClass string, accessMode v1.PersistentVolumeAccessMode) error {
 
 	_, err := clientset.CoreV1().PersistentVolumeClaims(namespace).Get(context.TODO(), name, metav1.GetOptions{})
 
@@ -153,7 +311,7 @@ func CreatePVC(clientset *kubernetes.Clientset, name string, namespace string, s
 		},
 		Spec: v1.PersistentVolumeClaimSpec{
 			AccessModes: []v1.PersistentVolumeAccessMode{
-				v1.ReadWriteMany,
+				accessMode,
 			},
 			Resources: v1.VolumeResourceRequirements{
 				Requests: v1.ResourceList{
@@ -162,6 +320,9 @@ func CreatePVC(clientset *kubernetes.Clientset, name string, namespace string, s
 			},
 		},
 	}
+	if storageClass != "" {
+		PVCSpec.Spec.StorageClassName = &storageClass
+	}
 
 	_, err = clientset.CoreV1().PersistentVolumeClaims(namespace).Create(context.TODO(), &PVCSpec, metav1.CreateOptions{})
 	if err != nil {
@@ -171,6 +332,195 @@ func CreatePVC(clientset *kubernetes.Clientset, name string, namespace string, s
 	return nil
 }
 
+// WaitForPVCBound waits until the claim is bound to a volume. Claims whose storage class
+// delays binding until a pod consumes them are only checked for provisioning failures.
+func WaitForPVCBound(clientset *kubernetes.Clientset, name string, namespace string, timeout time.Duration) error {
+	var pvc *v1.PersistentVolumeClaim
+
+	err := wait.PollUntilContextTimeout(context.Background(), 2*time.Second, timeout, true, func(ctx context.Context) (bool, error) {
+		// the last claim read is kept for the timeout message, as a failed get returns none
+		claim, err := clientset.CoreV1().PersistentVolumeClaims(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		pvc = claim
+		if pvc.Status.Phase == v1.ClaimBound {
+			return true, nil
+		}
+
+		storageClass, err := claimStorageClass(ctx, clientset, pvc)
+		if err != nil {
+			return false, err
+		}
+		if storageClass.VolumeBindingMode != nil && *storageClass.VolumeBindingMode == storagev1.VolumeBindingWaitForFirstConsumer {
+			fmt.Printf("storage class %s binds persistent volume claim %s when the first pod uses it\n", storageClass.Name, name)
+			return true, nil
+		}
+
+		events, err := clientset.CoreV1().Events(namespace).List(ctx, metav1.ListOptions{
+			FieldSelector: fields.Set{
+				"involvedObject.kind": "PersistentVolumeClaim",
+				"involvedObject.name": name,
+			}.String(),
+		})
+		if err != nil {
+			return false, err
+		}
+		for _, event := range events.Items {
+			if event.Type == v1.EventTypeWarning && event.Reason == "ProvisioningFailed" {
+				return false, fmt.Errorf("persistent volume claim %s could not be provisioned: %s", name, event.Message)
+			}
+		}
+		return false, nil
+	})
+
+	if wait.Interrupted(err) {
+		if pvc == nil {
+			return fmt.Errorf("could not read persistent volume claim %s within %s", name, timeout)
+		}
+		return fmt.Errorf("persistent volume claim %s is still %s after %s. check that storage class supports access mode %v", name, pvc.Status.Phase, timeout, pvc.Spec.AccessModes)
+	}
+	return err
+}
+
+func claimStorageClass(ctx context.Context, clientset *kubernetes.Clientset, pvc *v1.PersistentVolumeClaim) (*storagev1.StorageClass, error) {
+	if pvc.Spec.StorageClassName != nil && *pvc.Spec.StorageClassName != "" {
+		storageClass, err := clientset.StorageV1().StorageClasses().Get(ctx, *pvc.Spec.StorageClassName, metav1.GetOptions{})
+		if errors.IsNotFound(err) {
+			return nil, fmt.Errorf("storage class %s of persistent volume claim %s does not exist", *pvc.Spec.StorageClassName, pvc.Name)
+		}
+		return storageClass, err
+	}
+
+	storageClasses, err := clientset.StorageV1().StorageClasses().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for i, storageClass := range storageClasses.Items {
+		if storageClass.Annotations["storageclass.kubernetes.io/is-default-class"] == "true" {
+			return &storageClasses.Items[i], nil
+		}
+	}
+	return nil, fmt.Errorf("persistent volume claim %s has no storage class and the cluster has no default storage class", pvc.Name)
+}
+
+// FindGrafanaService returns the grafana service of the monitoring stack of
+// the profile: the service recorded for an adopted install, or the grafana
+// service of the monitoring namespace.
+func FindGrafanaService(clientset *kubernetes.Clientset, profile types.Profiles) (*v1.Service, error) {
+	namespace, name := profile.Namespace(types.ComponentMonitoring), "grafana"
+	if profile.Monitoring != nil && profile.Monitoring.Namespace != "" {
+		namespace = profile.Monitoring.Namespace
+	}
+	if profile.Monitoring != nil && profile.Monitoring.GrafanaService != "" {
+		name = profile.Monitoring.GrafanaService
+	}
+
+	svc, err := clientset.CoreV1().Services(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if err == nil {
+		return svc, nil
+	} else if !errors.IsNotFound(err) {
+		return nil, err
+	}
+	return LookupGrafanaService(clientset, namespace)
+}
+
+// LookupGrafanaService looks for a service labeled as grafana, in the
+// namespace first and in every namespace otherwise.
+func LookupGrafanaService(clientset *kubernetes.Clientset, namespace string) (*v1.Service, error) {
+	for _, ns := range []string{namespace, ""} {
+		services, err := clientset.CoreV1().Services(ns).List(context.TODO(), metav1.ListOptions{
+			LabelSelector: "app.kubernetes.io/name=grafana",
+		})
+		if err != nil {
+			return nil, err
+		}
+		if len(services.Items) > 0 {
+			return &services.Items[0], nil
+		}
+	}
+	return nil, fmt.Errorf("could not find a grafana service on the cluster")
+}
+
+// ComponentHealthy reports whether every workload of the component exists
+// and has all of its replicas ready. Workloads are looked up as deployments
+// first and as stateful sets otherwise.
+func ComponentHealthy(clientset *kubernetes.Clientset, component types.Component) (bool, error) {
+	for _, name := range component.Workloads {
+		var desired, ready int32
+
+		deployment, err := clientset.AppsV1().Deployments(component.Namespace).Get(context.TODO(), name, metav1.GetOptions{})
+		if errors.IsNotFound(err) {
+			statefulSet, err := clientset.AppsV1().StatefulSets(component.Namespace).Get(context.TODO(), name, metav1.GetOptions{})
+			if errors.IsNotFound(err) {
+				return false, nil
+			} else if err != nil {
+				return false, err
+			}
+			desired, ready = 1, statefulSet.Status.ReadyReplicas
+			if statefulSet.Spec.Replicas != nil {
+				desired = *statefulSet.Spec.Replicas
+			}
+		} else if err != nil {
+			return false, err
+		} else {
+			desired, ready = 1, deployment.Status.ReadyReplicas
+			if deployment.Spec.Replicas != nil {
+				desired = *deployment.Spec.Replicas
+			}
+		}
+
+		if ready < desired {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// WaitForComponentRollout waits until every workload of the component runs
+// its latest revision with all of its replicas ready.
+func WaitForComponentRollout(clientset *kubernetes.Clientset, component types.Component, timeout time.Duration) error {
+	err := wait.PollUntilContextTimeout(context.Background(), 5*time.Second, timeout, true, func(ctx context.Context) (bool, error) {
+		for _, name := range component.Workloads {
+			var generation, observedGeneration int64
+			var desired, updated, ready int32
+
+			deployment, err := clientset.AppsV1().Deployments(component.Namespace).Get(ctx, name, metav1.GetOptions{})
+			if errors.IsNotFound(err) {
+				statefulSet, err := clientset.AppsV1().StatefulSets(component.Namespace).Get(ctx, name, metav1.GetOptions{})
+				if errors.IsNotFound(err) {
+					return false, nil
+				} else if err != nil {
+					return false, err
+				}
+				generation, observedGeneration = statefulSet.Generation, statefulSet.Status.ObservedGeneration
+				desired, updated, ready = 1, statefulSet.Status.UpdatedReplicas, statefulSet.Status.ReadyReplicas
+				if statefulSet.Spec.Replicas != nil {
+					desired = *statefulSet.Spec.Replicas
+				}
+			} else if err != nil {
+				return false, err
+			} else {
+				generation, observedGeneration = deployment.Generation, deployment.Status.ObservedGeneration
+				desired, updated, ready = 1, deployment.Status.UpdatedReplicas, deployment.Status.ReadyReplicas
+				if deployment.Spec.Replicas != nil {
+					desired = *deployment.Spec.Replicas
+				}
+			}
+
+			if observedGeneration < generation || updated < desired || ready < desired {
+				return false, nil
+			}
+		}
+		return true, nil
+	})
+
+	if wait.Interrupted(err) {
+		return fmt.Errorf("%s did not become ready within %s", component.Name, timeout)
+	}
+	return err
+}
+
 func CreateJob(clientset *kubernetes.Clientset, job batchv1.Job) (jobInterface *batchv1.Job, err error) {
 
 	_, err = clientset.BatchV1().Jobs(job.Namespace).Get(context.TODO(), job.Name, metav1.GetOptions{})
@@ -186,6 +536,78 @@ func CreateJob(clientset *kubernetes.Clientset, job batchv1.Job) (jobInterface *
 	return
 }
 
+// WaitForJob waits until the job completes or fails, and returns it.
+func WaitForJob(clientset *kubernetes.Clientset, name string, namespace string, timeout time.Duration) (*batchv1.Job, error) {
+	var job *batchv1.Job
+
+	err := wait.PollUntilContextTimeout(context.Background(), 2*time.Second, timeout, true, func(ctx context.Context) (bool, error) {
+		current, err := clientset.BatchV1().Jobs(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		job = current
+		for _, condition := range job.Status.Conditions {
+			if (condition.Type == batchv1.JobComplete || condition.Type == batchv1.JobFailed) && condition.Status == v1.ConditionTrue {
+				return true, nil
+			}
+		}
+		return false, nil
+	})
+
+	if wait.Interrupted(err) {
+		return job, fmt.Errorf("job %s did not finish within %s", name, timeout)
+	}
+	return job, err
+}
+
+// JobFailed reports whether the job failed.
+func JobFailed(job *batchv1.Job) bool {
+	for _, condition := range job.Status.Conditions {
+		if condition.Type == batchv1.JobFailed && condition.Status == v1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
+
+// JobLogs returns the logs of the pods of the job.
+func JobLogs(clientset *kubernetes.Clientset, name string, namespace string) (string, error) {
+	pods, err := clientset.CoreV1().Pods(namespace).List(context.TODO(), metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("job-name=%s", name),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	var logs strings.Builder
+	for _, pod := range pods.Items {
+		data, err := clientset.CoreV1().Pods(namespace).GetLogs(pod.Name, &v1.PodLogOptions{}).DoRaw(context.TODO())
+		if err != nil {
+			return logs.String(), fmt.Errorf("could not read the logs of pod %s: %v", pod.Name, err)
+		}
+		fmt.Fprintf(&logs, "pod %s:\n%s", pod.Name, data)
+	}
+	return logs.String(), nil
+}
+
+func CreateService(clientset *kubernetes.Clientset, service v1.Service) (*v1.Service, error) {
+	_, err := clientset.CoreV1().Services(service.Namespace).Get(context.TODO(), service.Name, metav1.GetOptions{})
+	if !errors.IsNotFound(err) {
+		return nil, errors.NewAlreadyExists(schema.GroupResource{Resource: "Service"}, service.Name)
+	}
+
+	return clientset.CoreV1().Services(service.Namespace).Create(context.TODO(), &service, metav1.CreateOptions{})
+}
+
+func CreateIngress(clientset *kubernetes.Clientset, ingress networkingv1.Ingress) (*networkingv1.Ingress, error) {
+	_, err := clientset.NetworkingV1().Ingresses(ingress.Namespace).Get(context.TODO(), ingress.Name, metav1.GetOptions{})
+	if !errors.IsNotFound(err) {
+		return nil, errors.NewAlreadyExists(schema.GroupResource{Group: "networking.k8s.io", Resource: "Ingress"}, ingress.Name)
+	}
+
+	return clientset.NetworkingV1().Ingresses(ingress.Namespace).Create(context.TODO(), &ingress, metav1.CreateOptions{})
+}
+
 func CreatePod(clientset *kubernetes.Clientset, podspec v1.Pod) (pod *v1.Pod, err error) {
 
 	pod, err = clientset.CoreV1().Pods(podspec.Namespace).Get(context.TODO(), podspec.Name, metav1.GetOptions{})
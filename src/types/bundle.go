@@ -0,0 +1,25 @@
+package types
+
+const (
+	ArtifactManifest = "manifest"
+	ArtifactChart    = "chart"
+
+	BundleManifestFile = "bundle.json"
+)
+
+// Artifact is a manifest or helm chart init installs. Bundled artifacts
+// record the file holding them and its checksum.
+type Artifact struct {
+	Component string `json:"component"`
+	Type      string `json:"type"`
+	Source    string `json:"source"`
+	Chart     string `json:"chart,omitempty"`
+	Version   string `json:"version,omitempty"`
+	File      string `json:"file,omitempty"`
+	Sha256    string `json:"sha256,omitempty"`
+}
+
+// BundleManifest describes the artifacts of a bundle created with 'beamstack bundle create'.
+type BundleManifest struct {
+	Artifacts []Artifact `json:"artifacts"`
+}
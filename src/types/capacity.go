@@ -0,0 +1,45 @@
+package types
+
+import (
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+type NodeCapacity struct {
+	Node        v1.Node
+	Allocatable v1.ResourceList
+	Requested   v1.ResourceList
+}
+
+// Free returns the allocatable resources of the node that are not requested by its pods.
+func (n NodeCapacity) Free() v1.ResourceList {
+	free := v1.ResourceList{}
+	for name, allocatable := range n.Allocatable {
+		quantity := allocatable.DeepCopy()
+		if requested, ok := n.Requested[name]; ok {
+			quantity.Sub(requested)
+		}
+		if quantity.Sign() < 0 {
+			quantity = resource.Quantity{Format: quantity.Format}
+		}
+		free[name] = quantity
+	}
+	return free
+}
+
+type ClusterCapacity struct {
+	Nodes []NodeCapacity
+}
+
+type CapacityReport struct {
+	Requested v1.ResourceList
+	Free      v1.ResourceList
+	Shortfall v1.ResourceList
+	// Unplaced counts the pods that fit on no single node even if the cluster has enough resources in total
+	Unplaced int
+}
+
+// Fits reports whether every pod of the request can be placed on the cluster.
+func (r CapacityReport) Fits() bool {
+	return len(r.Shortfall) == 0 && r.Unplaced == 0
+}
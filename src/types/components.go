@@ -0,0 +1,225 @@
+package types
+
+import (
+	"fmt"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+const (
+	ComponentCertManager   = "cert-manager"
+	ComponentFlink         = "flink-operator"
+	ComponentMonitoring    = "monitoring"
+	ComponentElasticsearch = "elasticsearch"
+	ComponentKafka         = "kafka"
+	ComponentObjectStore   = "object-store"
+)
+
+// Versions init installs and upgrade upgrades to by default.
+const (
+	CertManagerVersion   = "1.8.2"
+	EckVersion           = "2.14.0"
+	FlinkOperatorVersion = "1.8.0"
+)
+
+const (
+	PrometheusChartsRepo = "https://prometheus-community.github.io/helm-charts"
+	MinioChartsRepo      = "https://charts.min.io/"
+)
+
+// CertManagerUrl returns the manifest of a cert-manager version.
+func CertManagerUrl(version string) string {
+	return fmt.Sprintf("https://github.com/jetstack/cert-manager/releases/download/v%s/cert-manager.yaml", version)
+}
+
+// EckCrdsUrl returns the custom resource definitions manifest of an ECK version.
+func EckCrdsUrl(version string) string {
+	return fmt.Sprintf("https://download.elastic.co/downloads/eck/%s/crds.yaml", version)
+}
+
+// EckOperatorUrl returns the operator manifest of an ECK version.
+func EckOperatorUrl(version string) string {
+	return fmt.Sprintf("https://download.elastic.co/downloads/eck/%s/operator.yaml", version)
+}
+
+// FlinkOperatorChartsRepo returns the chart repository of a flink kubernetes operator version.
+func FlinkOperatorChartsRepo(version string) string {
+	return fmt.Sprintf("https://downloads.apache.org/flink/flink-kubernetes-operator-%s/", version)
+}
+
+// eckCrds are the custom resource definitions installed with the ECK operator.
+var eckCrds = []string{
+	"agents.agent.k8s.elastic.co",
+	"apmservers.apm.k8s.elastic.co",
+	"beats.beat.k8s.elastic.co",
+	"elasticmapsservers.maps.k8s.elastic.co",
+	"elasticsearchautoscalers.autoscaling.k8s.elastic.co",
+	"elasticsearches.elasticsearch.k8s.elastic.co",
+	"enterprisesearches.enterprisesearch.k8s.elastic.co",
+	"kibanas.kibana.k8s.elastic.co",
+	"logstashes.logstash.k8s.elastic.co",
+	"stackconfigpolicies.stackconfigpolicy.k8s.elastic.co",
+}
+
+// CertManagerPackage returns the package recorded for a cert-manager version.
+func CertManagerPackage(version string) Package {
+	return Package{
+		Name:    "cert-manager",
+		Url:     CertManagerUrl(version),
+		Type:    "k8s",
+		Version: version,
+	}
+}
+
+// FlinkOperatorPackage returns the package recorded for a flink kubernetes operator version.
+func FlinkOperatorPackage(version string) Package {
+	return Package{
+		Name:    "flink-kubernetes-operator",
+		Url:     FlinkOperatorChartsRepo(version),
+		Type:    "helm",
+		Version: version,
+	}
+}
+
+// EckPackage returns the package recorded for an ECK version, with its
+// custom resource definitions as dependencies.
+func EckPackage(version string) Package {
+	eck := Package{
+		Name:    "elasticsearch",
+		Url:     EckOperatorUrl(version),
+		Type:    "k8s",
+		Version: version,
+	}
+	for _, crd := range eckCrds {
+		eck.Dependencies = append(eck.Dependencies, &Package{
+			Name:    fmt.Sprintf("crds/%s", crd),
+			Url:     EckCrdsUrl(version),
+			Type:    "k8s",
+			Version: version,
+		})
+	}
+	return eck
+}
+
+// ComponentArtifacts returns the manifests and charts init installs for a
+// component of the configuration.
+func ComponentArtifacts(name string, config ProfileConfig) []Artifact {
+	version := config.Version(name)
+	switch name {
+	case ComponentCertManager:
+		return []Artifact{{Component: name, Type: ArtifactManifest, Source: CertManagerUrl(version)}}
+	case ComponentFlink:
+		return []Artifact{{Component: name, Type: ArtifactChart, Source: FlinkOperatorChartsRepo(version), Chart: "flink-kubernetes-operator", Version: version}}
+	case ComponentMonitoring:
+		return []Artifact{{Component: name, Type: ArtifactChart, Source: PrometheusChartsRepo, Chart: "kube-prometheus-stack", Version: version}}
+	case ComponentElasticsearch:
+		return []Artifact{
+			{Component: name, Type: ArtifactManifest, Source: EckCrdsUrl(version)},
+			{Component: name, Type: ArtifactManifest, Source: EckOperatorUrl(version)},
+		}
+	case ComponentKafka:
+		return []Artifact{{Component: name, Type: ArtifactManifest, Source: StrimziInstallUrl(config.Namespace(name))}}
+	case ComponentObjectStore:
+		return []Artifact{{Component: name, Type: ArtifactChart, Source: MinioChartsRepo, Chart: ObjectStoreRelease, Version: version}}
+	}
+	return nil
+}
+
+// Component describes a component beamstack installs on a cluster, along
+// with the workloads that have to be ready for it to be healthy.
+// Version is the version init installs by default, empty for the latest
+// release of a chart. MinKubeVersion is the oldest kubernetes version the
+// installed release supports and Requests approximates the resources its
+// workloads request.
+type Component struct {
+	Name           string
+	Namespace      string
+	Version        string
+	Workloads      []string
+	MinKubeVersion string
+	Requests       v1.ResourceList
+}
+
+func componentRequests(cpu string, memory string) v1.ResourceList {
+	return v1.ResourceList{
+		v1.ResourceCPU:    resource.MustParse(cpu),
+		v1.ResourceMemory: resource.MustParse(memory),
+	}
+}
+
+// Components is the catalog of installable components, in install order.
+var Components = []Component{
+	{
+		Name:           ComponentCertManager,
+		Namespace:      "cert-manager",
+		Version:        CertManagerVersion,
+		Workloads:      []string{"cert-manager", "cert-manager-cainjector", "cert-manager-webhook"},
+		MinKubeVersion: "1.19",
+		Requests:       componentRequests("100m", "256Mi"),
+	},
+	{
+		Name:           ComponentFlink,
+		Namespace:      "flink",
+		Version:        FlinkOperatorVersion,
+		Workloads:      []string{"flink-kubernetes-operator"},
+		MinKubeVersion: "1.20",
+		Requests:       componentRequests("500m", "1Gi"),
+	},
+	{
+		Name:           ComponentMonitoring,
+		Namespace:      "monitoring",
+		Workloads:      []string{"grafana", "prometheus-operator", "kube-state-metrics"},
+		MinKubeVersion: "1.19",
+		Requests:       componentRequests("1", "2Gi"),
+	},
+	{
+		Name:           ComponentElasticsearch,
+		Namespace:      "elastic-system",
+		Version:        EckVersion,
+		Workloads:      []string{"elastic-operator"},
+		MinKubeVersion: "1.27",
+		Requests:       componentRequests("100m", "200Mi"),
+	},
+	{
+		Name:           ComponentKafka,
+		Namespace:      KafkaNamespace,
+		Version:        "latest",
+		Workloads:      []string{"strimzi-cluster-operator"},
+		MinKubeVersion: "1.25",
+		Requests:       componentRequests("200m", "384Mi"),
+	},
+	{
+		Name:           ComponentObjectStore,
+		Namespace:      ObjectStoreNamespace,
+		Workloads:      []string{ObjectStoreRelease},
+		MinKubeVersion: "1.19",
+		Requests:       componentRequests("250m", "512Mi"),
+	},
+}
+
+// GetComponent returns the catalog entry of a component.
+func GetComponent(name string) (Component, bool) {
+	for _, component := range Components {
+		if component.Name == name {
+			return component, true
+		}
+	}
+	return Component{}, false
+}
+
+const (
+	StepPending   = "pending"
+	StepCompleted = "completed"
+	StepFailed    = "failed"
+)
+
+// InitStep records the progress of an init step in the profile, so an
+// interrupted init can be resumed.
+type InitStep struct {
+	Name        string     `json:"name"`
+	Status      string     `json:"status"`
+	Error       string     `json:"error,omitempty"`
+	CompletedAt *time.Time `json:"completedAt,omitempty"`
+}
@@ -1,12 +1,88 @@
 package types
 
+import (
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
 type EsNodeSet struct {
-	Name   string      `yaml:"name"`
-	Count  uint16      `yaml:"count"`
-	Config map[string]interface{} `yaml:"config"`
+	Name                 string                     `json:"name"`
+	Count                uint16                     `json:"count"`
+	Config               map[string]interface{}     `json:"config,omitempty"`
+	VolumeClaimTemplates []v1.PersistentVolumeClaim `json:"volumeClaimTemplates,omitempty"`
+	PodTemplate          *v1.PodTemplateSpec        `json:"podTemplate,omitempty"`
 }
 
 type EsSpec struct {
-	Version  string      `yaml:"version"`
-	NodeSets []EsNodeSet `yaml:"nodeSets"`
+	Version  string      `json:"version"`
+	HTTP     *EsHTTP     `json:"http,omitempty"`
+	NodeSets []EsNodeSet `json:"nodeSets"`
+}
+
+type EsHTTP struct {
+	TLS EsTLS `json:"tls"`
+}
+
+type EsTLS struct {
+	SelfSignedCertificate *EsSelfSignedCertificate `json:"selfSignedCertificate,omitempty"`
+}
+
+type EsSelfSignedCertificate struct {
+	Disabled bool `json:"disabled,omitempty"`
+}
+
+// TLS reports whether the cluster serves https. ECK enables TLS with a
+// self-signed certificate unless it is disabled.
+func (s EsSpec) TLS() bool {
+	return s.HTTP == nil || s.HTTP.TLS.SelfSignedCertificate == nil || !s.HTTP.TLS.SelfSignedCertificate.Disabled
+}
+
+var EsGVR = schema.GroupVersionResource{
+	Group:    "elasticsearch.k8s.elastic.co",
+	Version:  "v1",
+	Resource: "elasticsearches",
+}
+
+type Elasticsearch struct {
+	metav1.ObjectMeta `json:"metadata"`
+	Spec              EsSpec `json:"spec"`
+}
+
+type KibanaElasticsearchRef struct {
+	Name string `json:"name"`
+}
+
+type KibanaSpec struct {
+	Version          string                 `json:"version"`
+	Count            uint16                 `json:"count"`
+	ElasticsearchRef KibanaElasticsearchRef `json:"elasticsearchRef"`
+}
+
+const (
+	// names ECK expects for the data volume and the elasticsearch container
+	EsDataVolumeName = "elasticsearch-data"
+	EsContainerName  = "elasticsearch"
+)
+
+// EsURL returns the in-cluster url of an elasticsearch cluster.
+func EsURL(name string, namespace string, tls bool) string {
+	scheme := "http"
+	if tls {
+		scheme = "https"
+	}
+	return fmt.Sprintf("%s://%s-es-http.%s.svc.cluster.local:9200", scheme, name, namespace)
+}
+
+// EsCASecret returns the secret holding the CA certificate of the self-signed
+// http certificate of an elasticsearch cluster.
+func EsCASecret(name string) string {
+	return fmt.Sprintf("%s-es-http-certs-public", name)
+}
+
+// EsCredentialsSecret returns the secret holding the password of the elastic user.
+func EsCredentialsSecret(name string) string {
+	return fmt.Sprintf("%s-es-elastic-user", name)
 }
@@ -1,7 +1,11 @@
 package types
 
 import (
+	"fmt"
+
 	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
 type FlinkDeploymentSpec struct {
@@ -43,3 +47,45 @@ type JobSpec struct {
 	Parallelism   uint8            `yaml:"parallelism,omitempty"`
 	RestartPolicy v1.RestartPolicy `yaml:"restartPolicy,omitempty"`
 }
+
+// ClaimNameAnnotation records the persistent volume claim mounted by a flink cluster.
+const ClaimNameAnnotation = "beamstack.io/claim-name"
+
+var FlinkDeploymentGVR = schema.GroupVersionResource{
+	Group:    "flink.apache.org",
+	Version:  "v1beta1",
+	Resource: "flinkdeployments",
+}
+
+type FlinkDeployment struct {
+	metav1.ObjectMeta `json:"metadata"`
+	Spec              FlinkDeploymentSpec   `json:"spec"`
+	Status            FlinkDeploymentStatus `json:"status"`
+}
+
+type FlinkDeploymentStatus struct {
+	LifecycleState             string `json:"lifecycleState,omitempty"`
+	JobManagerDeploymentStatus string `json:"jobManagerDeploymentStatus,omitempty"`
+}
+
+// ClaimName returns the persistent volume claim mounted by the cluster.
+func (d FlinkDeployment) ClaimName() string {
+	if name, ok := d.Annotations[ClaimNameAnnotation]; ok {
+		return name
+	}
+	return fmt.Sprintf("%s-pvc", d.Name)
+}
+
+// Harness returns the SDK harness environment the cluster was created for.
+func (d FlinkDeployment) Harness() string {
+	if harness, ok := d.Annotations[HarnessAnnotation]; ok {
+		return harness
+	}
+	return HarnessExternal
+}
+
+// HighAvailability reports whether the deployment runs with Kubernetes HA services.
+func (s FlinkDeploymentSpec) HighAvailability() bool {
+	mode, ok := s.FlinkConfiguration["high-availability"]
+	return ok && mode != "" && mode != "NONE"
+}
@@ -0,0 +1,83 @@
+package types
+
+// Types of the Flink REST API responses used by the flink handler.
+
+type FlinkJobOverview struct {
+	ID        string `json:"jid"`
+	Name      string `json:"name"`
+	State     string `json:"state"`
+	StartTime int64  `json:"start-time"`
+	EndTime   int64  `json:"end-time"`
+	Duration  int64  `json:"duration"`
+}
+
+type FlinkJobsOverview struct {
+	Jobs []FlinkJobOverview `json:"jobs"`
+}
+
+type FlinkJobDetails struct {
+	ID       string        `json:"jid"`
+	Name     string        `json:"name"`
+	State    string        `json:"state"`
+	Duration int64         `json:"duration"`
+	Vertices []FlinkVertex `json:"vertices"`
+}
+
+type FlinkVertex struct {
+	ID          string             `json:"id"`
+	Name        string             `json:"name"`
+	Parallelism int                `json:"parallelism"`
+	Status      string             `json:"status"`
+	Duration    int64              `json:"duration"`
+	Metrics     FlinkVertexMetrics `json:"metrics"`
+}
+
+type FlinkVertexMetrics struct {
+	ReadBytes    int64 `json:"read-bytes"`
+	WriteBytes   int64 `json:"write-bytes"`
+	ReadRecords  int64 `json:"read-records"`
+	WriteRecords int64 `json:"write-records"`
+}
+
+type FlinkJobExceptions struct {
+	History FlinkExceptionHistory `json:"exceptionHistory"`
+}
+
+type FlinkExceptionHistory struct {
+	Entries   []FlinkException `json:"entries"`
+	Truncated bool             `json:"truncated"`
+}
+
+type FlinkException struct {
+	Name       string  `json:"exceptionName"`
+	Stacktrace string  `json:"stacktrace"`
+	Timestamp  int64   `json:"timestamp"`
+	TaskName   *string `json:"taskName"`
+}
+
+type FlinkSavepointRequest struct {
+	TargetDirectory string `json:"target-directory,omitempty"`
+	CancelJob       bool   `json:"cancel-job"`
+}
+
+type FlinkTriggerResponse struct {
+	RequestID string `json:"request-id"`
+}
+
+type FlinkSavepointStatus struct {
+	Status struct {
+		ID string `json:"id"`
+	} `json:"status"`
+	Operation *struct {
+		Location     string `json:"location"`
+		FailureCause *struct {
+			Class      string `json:"class"`
+			Stacktrace string `json:"stack-trace"`
+		} `json:"failure-cause"`
+	} `json:"operation"`
+}
+
+type FlinkConfigEntry struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
@@ -0,0 +1,26 @@
+package types
+
+// SDK harness environments the workers of a beam pipeline can run in.
+const (
+	// HarnessExternal runs a worker pool sidecar next to every task manager.
+	HarnessExternal = "external"
+	// HarnessDocker lets task managers start harness containers through the node docker socket.
+	HarnessDocker = "docker"
+	// HarnessProcess starts the harness as a process inside the task manager container.
+	HarnessProcess = "process"
+	// HarnessLoopback runs the harness next to the submitting pipeline job instead of the task managers.
+	HarnessLoopback = "loopback"
+)
+
+var Harnesses = []string{HarnessExternal, HarnessDocker, HarnessProcess, HarnessLoopback}
+
+// HarnessAnnotation records the SDK harness environment a flink cluster was created for.
+const HarnessAnnotation = "beamstack.io/harness"
+
+const (
+	BeamHarnessImage = "beamstackproj/beam-harness:latest"
+	// BeamBootPath is where the harness image keeps the beam boot binary.
+	BeamBootPath = "/opt/apache/beam"
+	// WorkerPoolPort is the port the harness worker pool listens on.
+	WorkerPoolPort = 50000
+)
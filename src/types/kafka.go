@@ -0,0 +1,97 @@
+package types
+
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	KafkaNamespace    = "kafka"
+	KafkaClusterLabel = "strimzi.io/cluster"
+)
+
+var (
+	KafkaGVR = schema.GroupVersionResource{
+		Group:    "kafka.strimzi.io",
+		Version:  "v1beta2",
+		Resource: "kafkas",
+	}
+	KafkaTopicGVR = schema.GroupVersionResource{
+		Group:    "kafka.strimzi.io",
+		Version:  "v1beta2",
+		Resource: "kafkatopics",
+	}
+)
+
+// StrimziInstallUrl returns the manifest of the latest strimzi release, installing
+// the operator in the namespace.
+func StrimziInstallUrl(namespace string) string {
+	return fmt.Sprintf("https://strimzi.io/install/latest?namespace=%s", namespace)
+}
+
+// KafkaPackage returns the package recorded for the strimzi kafka operator,
+// which is always installed from its latest release.
+func KafkaPackage(namespace string) Package {
+	return Package{
+		Name:      "strimzi-kafka-operator",
+		Url:       StrimziInstallUrl(namespace),
+		Namespace: namespace,
+		Type:      "k8s",
+		Version:   "latest",
+	}
+}
+
+type Kafka struct {
+	metav1.ObjectMeta `json:"metadata"`
+	Spec              KafkaSpec `json:"spec"`
+}
+
+type KafkaListener struct {
+	Name string `json:"name"`
+	Port int32  `json:"port"`
+	Type string `json:"type"`
+	Tls  bool   `json:"tls"`
+}
+
+type KafkaClusterSpec struct {
+	Version   string                 `json:"version,omitempty"`
+	Listeners []KafkaListener        `json:"listeners"`
+	Config    map[string]interface{} `json:"config,omitempty"`
+}
+
+type KafkaSpec struct {
+	Kafka          KafkaClusterSpec       `json:"kafka"`
+	EntityOperator map[string]interface{} `json:"entityOperator,omitempty"`
+}
+
+type KafkaVolume struct {
+	Id            int    `json:"id"`
+	Type          string `json:"type"`
+	Size          string `json:"size,omitempty"`
+	Class         string `json:"class,omitempty"`
+	DeleteClaim   bool   `json:"deleteClaim"`
+	KraftMetadata string `json:"kraftMetadata,omitempty"`
+}
+
+type KafkaStorage struct {
+	Type    string        `json:"type"`
+	Volumes []KafkaVolume `json:"volumes,omitempty"`
+}
+
+type KafkaNodePoolSpec struct {
+	Replicas int32        `json:"replicas"`
+	Roles    []string     `json:"roles"`
+	Storage  KafkaStorage `json:"storage"`
+}
+
+type KafkaTopicSpec struct {
+	Partitions int32 `json:"partitions"`
+	Replicas   int32 `json:"replicas"`
+}
+
+// KafkaBootstrapAddress returns the in-cluster address of the plain listener of a kafka cluster.
+func KafkaBootstrapAddress(name string, namespace string) string {
+	return fmt.Sprintf("%s-kafka-bootstrap.%s.svc.cluster.local:9092", name, namespace)
+}
@@ -0,0 +1,75 @@
+package types
+
+import (
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+const (
+	ObjectStoreNamespace = "minio"
+	ObjectStoreRelease   = "minio"
+	ObjectStoreSecret    = "minio-credentials"
+	MinioClientImage     = "minio/mc:latest"
+
+	// keys of the credentials secret, as expected by the minio chart
+	ObjectStoreUserKey     = "rootUser"
+	ObjectStorePasswordKey = "rootPassword"
+
+	// alias the minio client uses for the object store
+	ObjectStoreAlias = "store"
+
+	// variable holding the endpoint of the object store
+	ObjectStoreEndpointEnv = "OBJECT_STORE_ENDPOINT"
+)
+
+// ObjectStoreEndpoint returns the in-cluster endpoint of the object store installed in the namespace.
+func ObjectStoreEndpoint(namespace string) string {
+	return fmt.Sprintf("http://%s.%s.svc.cluster.local:9000", ObjectStoreRelease, namespace)
+}
+
+type ObjectStore struct {
+	Name              string `json:"name"`
+	Namespace         string `json:"namespace"`
+	Endpoint          string `json:"endpoint"`
+	CredentialsSecret string `json:"credentialsSecret"`
+}
+
+// CredentialsEnv returns the environment of containers talking to the object
+// store. The credentials are exposed as AWS_ACCESS_KEY_ID and
+// AWS_SECRET_ACCESS_KEY, next to the endpoint of the store.
+func (s ObjectStore) CredentialsEnv() []v1.EnvVar {
+	return []v1.EnvVar{
+		{
+			Name:  ObjectStoreEndpointEnv,
+			Value: s.Endpoint,
+		},
+		{
+			Name: "AWS_ACCESS_KEY_ID",
+			ValueFrom: &v1.EnvVarSource{
+				SecretKeyRef: &v1.SecretKeySelector{
+					LocalObjectReference: v1.LocalObjectReference{Name: s.CredentialsSecret},
+					Key:                  ObjectStoreUserKey,
+				},
+			},
+		},
+		{
+			Name: "AWS_SECRET_ACCESS_KEY",
+			ValueFrom: &v1.EnvVarSource{
+				SecretKeyRef: &v1.SecretKeySelector{
+					LocalObjectReference: v1.LocalObjectReference{Name: s.CredentialsSecret},
+					Key:                  ObjectStorePasswordKey,
+				},
+			},
+		},
+	}
+}
+
+// MinioClientCommand returns the command running the minio client with the
+// arguments in a container with the CredentialsEnv environment. The alias of
+// the store is set from the environment first, as credentials holding url
+// characters cannot be given in an MC_HOST_ url.
+func MinioClientCommand(args ...string) []string {
+	script := fmt.Sprintf(`mc alias set %s "$%s" "$AWS_ACCESS_KEY_ID" "$AWS_SECRET_ACCESS_KEY" >/dev/null && exec mc "$@"`, ObjectStoreAlias, ObjectStoreEndpointEnv)
+	return append([]string{"sh", "-c", script, "mc"}, args...)
+}
@@ -0,0 +1,43 @@
+package types
+
+const (
+	CheckPass = "pass"
+	CheckWarn = "warn"
+	CheckFail = "fail"
+)
+
+// CheckResult is the outcome of a single pre-flight check.
+type CheckResult struct {
+	Name    string
+	Status  string
+	Message string
+}
+
+// PreflightReport holds the outcome of the pre-flight checks run before init.
+type PreflightReport struct {
+	Checks []CheckResult
+}
+
+// Add records the outcome of a check.
+func (r *PreflightReport) Add(name string, status string, message string) {
+	r.Checks = append(r.Checks, CheckResult{Name: name, Status: status, Message: message})
+}
+
+// Failed reports whether any check failed.
+func (r PreflightReport) Failed() bool {
+	for _, check := range r.Checks {
+		if check.Status == CheckFail {
+			return true
+		}
+	}
+	return false
+}
+
+// ExternalInstall is an install of a component found on the cluster that was
+// not made by beamstack init, identified by the deployment of its controller.
+type ExternalInstall struct {
+	Component  string
+	Namespace  string
+	Deployment string
+	Version    string
+}
@@ -0,0 +1,198 @@
+package types
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// ProfileConfig is the content of a profile configuration file. It describes
+// everything init installs: the components, keyed by component name, with
+// their versions, namespaces, helm values overrides and credentials.
+// cert-manager is installed even if it is not listed, as the operators need it.
+// Scheduling holds the default scheduling constraints of the pods beamstack
+// generates, which the scheduling flags of the commands override.
+type ProfileConfig struct {
+	Name       string                     `json:"name,omitempty"`
+	Components map[string]ComponentConfig `json:"components"`
+	Scheduling *Scheduling                `json:"scheduling,omitempty"`
+}
+
+// ComponentConfig configures the install of a component. Empty fields fall
+// back to the defaults of the component. Values are merged over the helm
+// values of the component, after the values of ValuesFile.
+type ComponentConfig struct {
+	Version     string                 `json:"version,omitempty"`
+	Namespace   string                 `json:"namespace,omitempty"`
+	Size        string                 `json:"size,omitempty"`
+	ValuesFile  string                 `json:"valuesFile,omitempty"`
+	Values      map[string]interface{} `json:"values,omitempty"`
+	Credentials *CredentialsRef        `json:"credentials,omitempty"`
+}
+
+// CredentialsRef references the credentials of a component without holding
+// them. The password is read from a file or an environment variable, or the
+// credentials are taken from an existing secret in the namespace of the component.
+type CredentialsRef struct {
+	Username       string `json:"username,omitempty"`
+	PasswordFile   string `json:"passwordFile,omitempty"`
+	PasswordEnv    string `json:"passwordEnv,omitempty"`
+	ExistingSecret string `json:"existingSecret,omitempty"`
+}
+
+// components whose namespace is set by the manifests they are installed from
+var fixedNamespaces = []string{ComponentCertManager, ComponentElasticsearch}
+
+// components installed from a helm chart
+var chartComponents = []string{ComponentFlink, ComponentMonitoring, ComponentObjectStore}
+
+// components with credentials
+var credentialComponents = []string{ComponentMonitoring, ComponentObjectStore}
+
+// components installed once per cluster, as their cluster scoped resources
+// cannot be installed twice
+var sharedComponents = []string{ComponentCertManager, ComponentMonitoring, ComponentElasticsearch, ComponentKafka}
+
+// SharedComponent reports whether a component is shared by the environments of a cluster.
+func SharedComponent(name string) bool {
+	return contains(sharedComponents, name)
+}
+
+// ForEnvironment returns the configuration of an environment. Components that
+// are not shared get their own namespace in environments other than the
+// default one, suffixed with the environment name, unless it is set.
+func (c ProfileConfig) ForEnvironment(environment string) ProfileConfig {
+	if environment == DefaultEnvironment {
+		return c
+	}
+
+	components := map[string]ComponentConfig{}
+	for name, component := range c.Components {
+		if component.Namespace == "" && !SharedComponent(name) {
+			component.Namespace = fmt.Sprintf("%s-%s", c.Namespace(name), environment)
+		}
+		components[name] = component
+	}
+	c.Components = components
+	return c
+}
+
+// Has reports whether the configuration installs the component.
+func (c ProfileConfig) Has(name string) bool {
+	if name == ComponentCertManager {
+		return true
+	}
+	_, ok := c.Components[name]
+	return ok
+}
+
+// Component returns the configuration of a component.
+func (c ProfileConfig) Component(name string) ComponentConfig {
+	return c.Components[name]
+}
+
+// Version returns the version of a component, or the version init installs by
+// default. An empty version installs the latest release of a chart.
+func (c ProfileConfig) Version(name string) string {
+	if version := c.Components[name].Version; version != "" {
+		return strings.TrimPrefix(version, "v")
+	}
+	component, _ := GetComponent(name)
+	return component.Version
+}
+
+// Namespace returns the namespace of a component.
+func (c ProfileConfig) Namespace(name string) string {
+	if namespace := c.Components[name].Namespace; namespace != "" {
+		return namespace
+	}
+	component, _ := GetComponent(name)
+	return component.Namespace
+}
+
+// GetComponent returns the catalog entry of a component, in the namespace
+// the configuration installs it in.
+func (c ProfileConfig) GetComponent(name string) (Component, bool) {
+	component, ok := GetComponent(name)
+	if ok {
+		component.Namespace = c.Namespace(name)
+	}
+	return component, ok
+}
+
+// Validate checks that every component is known and only sets the fields
+// that apply to it.
+func (c ProfileConfig) Validate() error {
+	names := []string{}
+	for name := range c.Components {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		component := c.Components[name]
+		catalog, ok := GetComponent(name)
+		if !ok {
+			known := []string{}
+			for _, component := range Components {
+				known = append(known, component.Name)
+			}
+			return fmt.Errorf("unknown component %s. valid components are %s", name, strings.Join(known, ", "))
+		}
+
+		if component.Namespace != "" && contains(fixedNamespaces, name) && component.Namespace != catalog.Namespace {
+			return fmt.Errorf("%s: the namespace is set by the manifests of the component and cannot be changed", name)
+		}
+		if component.Version != "" && name == ComponentKafka && component.Version != "latest" {
+			return fmt.Errorf("%s: the strimzi kafka operator is only installed from its latest release", name)
+		}
+		if (len(component.Values) > 0 || component.ValuesFile != "") && !contains(chartComponents, name) {
+			return fmt.Errorf("%s: values only apply to components installed from a helm chart: %s", name, strings.Join(chartComponents, ", "))
+		}
+		if component.Size != "" {
+			if name != ComponentObjectStore {
+				return fmt.Errorf("%s: size only applies to %s", name, ComponentObjectStore)
+			}
+			if _, err := resource.ParseQuantity(component.Size); err != nil {
+				return fmt.Errorf("%s: invalid size %s: %v", name, component.Size, err)
+			}
+		}
+		if component.Credentials != nil {
+			if !contains(credentialComponents, name) {
+				return fmt.Errorf("%s: credentials only apply to %s", name, strings.Join(credentialComponents, ", "))
+			}
+			if err := component.Credentials.Validate(); err != nil {
+				return fmt.Errorf("%s: %v", name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Validate checks that the password comes from a single source.
+func (r CredentialsRef) Validate() error {
+	sources := 0
+	for _, source := range []string{r.PasswordFile, r.PasswordEnv, r.ExistingSecret} {
+		if source != "" {
+			sources++
+		}
+	}
+	if sources > 1 {
+		return fmt.Errorf("credentials take only one of passwordFile, passwordEnv and existingSecret")
+	}
+	if r.ExistingSecret != "" && r.Username != "" {
+		return fmt.Errorf("the username of credentials from an existing secret is read from the secret")
+	}
+	return nil
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
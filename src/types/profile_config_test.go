@@ -0,0 +1,84 @@
+package types
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestForEnvironment(t *testing.T) {
+	config := ProfileConfig{
+		Components: map[string]ComponentConfig{
+			ComponentFlink:       {},
+			ComponentObjectStore: {Namespace: "storage"},
+			ComponentMonitoring:  {},
+			ComponentKafka:       {},
+			ComponentCertManager: {},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		environment string
+		component   string
+		want        string
+	}{
+		{"default environment keeps the namespace", DefaultEnvironment, ComponentFlink, "flink"},
+		{"default environment keeps a set namespace", DefaultEnvironment, ComponentObjectStore, "storage"},
+		{"component gets a suffixed namespace", "team-a", ComponentFlink, "flink-team-a"},
+		{"set namespace is kept", "team-a", ComponentObjectStore, "storage"},
+		{"shared chart component is not suffixed", "team-a", ComponentMonitoring, "monitoring"},
+		{"shared operator is not suffixed", "team-a", ComponentKafka, KafkaNamespace},
+		{"shared prerequisite is not suffixed", "team-a", ComponentCertManager, "cert-manager"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := config.ForEnvironment(test.environment).Namespace(test.component); got != test.want {
+				t.Errorf("got namespace %s, want %s", got, test.want)
+			}
+		})
+	}
+
+	if config.Components[ComponentFlink].Namespace != "" {
+		t.Error("ForEnvironment modified the components of the configuration")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name      string
+		component string
+		config    ComponentConfig
+		err       string
+	}{
+		{"valid chart component", ComponentFlink, ComponentConfig{Version: "1.8.0", Namespace: "streaming", Values: map[string]interface{}{"replicas": 2}}, ""},
+		{"valid object store", ComponentObjectStore, ComponentConfig{Size: "20Gi", Credentials: &CredentialsRef{Username: "admin", PasswordEnv: "MINIO_PASSWORD"}}, ""},
+		{"unknown component", "spark", ComponentConfig{}, "unknown component spark"},
+		{"fixed namespace", ComponentCertManager, ComponentConfig{Namespace: "certs"}, "namespace is set by the manifests"},
+		{"fixed namespace unchanged", ComponentElasticsearch, ComponentConfig{Namespace: "elastic-system"}, ""},
+		{"kafka version", ComponentKafka, ComponentConfig{Version: "0.40.0"}, "only installed from its latest release"},
+		{"values of a manifest component", ComponentElasticsearch, ComponentConfig{Values: map[string]interface{}{"replicas": 2}}, "values only apply to components installed from a helm chart"},
+		{"values file of a manifest component", ComponentKafka, ComponentConfig{ValuesFile: "kafka.yaml"}, "values only apply to components installed from a helm chart"},
+		{"size of another component", ComponentFlink, ComponentConfig{Size: "20Gi"}, "size only applies to object-store"},
+		{"invalid size", ComponentObjectStore, ComponentConfig{Size: "twenty"}, "invalid size twenty"},
+		{"credentials of another component", ComponentFlink, ComponentConfig{Credentials: &CredentialsRef{PasswordEnv: "PASSWORD"}}, "credentials only apply to"},
+		{"several password sources", ComponentMonitoring, ComponentConfig{Credentials: &CredentialsRef{PasswordFile: "password", PasswordEnv: "PASSWORD"}}, "only one of passwordFile, passwordEnv and existingSecret"},
+		{"username of an existing secret", ComponentMonitoring, ComponentConfig{Credentials: &CredentialsRef{Username: "admin", ExistingSecret: "grafana"}}, "read from the secret"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			config := ProfileConfig{Components: map[string]ComponentConfig{test.component: test.config}}
+			err := config.Validate()
+			if test.err == "" {
+				if err != nil {
+					t.Errorf("unexpected error %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Errorf("got error %v, want one containing %q", err, test.err)
+			}
+		})
+	}
+}
@@ -20,14 +20,19 @@ type Operator struct {
 	Spark *OperatorDetails `json:"spark,omitempty"`
 }
 
+// Monitoring records the monitoring stack of the cluster. Namespace and
+// GrafanaService locate the grafana service of an adopted install.
 type Monitoring struct {
-	Name string `json:"name"`
+	Name           string `json:"name"`
+	Namespace      string `json:"namespace,omitempty"`
+	GrafanaService string `json:"grafanaService,omitempty"`
 }
 
 type Package struct {
 	Name         string     `json:"name"`
 	Type         string     `json:"type,omitempty"`
 	Version      string     `json:"version,omitempty"`
+	Namespace    string     `json:"namespace,omitempty"`
 	Dependencies []*Package `json:"dependencies,omitempty"`
 	Url          string     `json:"url,omitempty"`
 	*Timestamp
@@ -41,10 +46,93 @@ type Package struct {
 // }
 
 type Profiles struct {
-	Name       string      `json:"name"`
-	Operators  Operator    `json:"operators"`
-	Monitoring *Monitoring `json:"monitoring,omitempty"`
-	Packages   []Package   `json:"packages"`
+	Name        string       `json:"name"`
+	Operators   Operator     `json:"operators"`
+	Monitoring  *Monitoring  `json:"monitoring,omitempty"`
+	ObjectStore *ObjectStore `json:"objectStore,omitempty"`
+	Packages    []Package    `json:"packages"`
+	Scheduling  *Scheduling  `json:"scheduling,omitempty"`
+	Exposures   []Exposure   `json:"exposures,omitempty"`
+	Steps       []InitStep   `json:"steps,omitempty"`
+	// Components is the configuration init installed the profile from
+	Components map[string]ComponentConfig `json:"components,omitempty"`
+	// Environment is the beamstack environment of the cluster the profile was installed as
+	Environment string `json:"environment,omitempty"`
+}
+
+// DefaultEnvironment is the environment of commands run without --env.
+const DefaultEnvironment = "default"
+
+// ProfileEnvironmentLabel records the beamstack environment that created a resource.
+const ProfileEnvironmentLabel = "beamstack.io/env"
+
+// EnvironmentName returns the beamstack environment of the profile.
+func (c Profiles) EnvironmentName() string {
+	if c.Environment == "" {
+		return DefaultEnvironment
+	}
+	return c.Environment
+}
+
+// Owns reports whether a resource with the labels belongs to the environment
+// of the profile. Unlabeled resources predate environments and belong to all.
+func (c Profiles) Owns(labels map[string]string) bool {
+	environment, ok := labels[ProfileEnvironmentLabel]
+	return !ok || environment == c.EnvironmentName()
+}
+
+// Config returns the configuration init installed the profile from.
+func (c Profiles) Config() ProfileConfig {
+	return ProfileConfig{
+		Name:       c.Name,
+		Components: c.Components,
+		Scheduling: c.Scheduling,
+	}
+}
+
+// Namespace returns the namespace init installed a component in.
+func (c Profiles) Namespace(component string) string {
+	return c.Config().Namespace(component)
+}
+
+// Step returns the recorded state of an init step, or nil if the step is not part of the profile.
+func (c *Profiles) Step(name string) *InitStep {
+	for i := range c.Steps {
+		if c.Steps[i].Name == name {
+			return &c.Steps[i]
+		}
+	}
+	return nil
+}
+
+// SetStep records the state of an init step.
+func (c *Profiles) SetStep(name string, status string, err error) {
+	step := c.Step(name)
+	if step == nil {
+		c.Steps = append(c.Steps, InitStep{Name: name})
+		step = &c.Steps[len(c.Steps)-1]
+	}
+
+	step.Status = status
+	step.Error = ""
+	if err != nil {
+		step.Error = err.Error()
+	}
+	step.CompletedAt = nil
+	if status == StepCompleted {
+		now := time.Now()
+		step.CompletedAt = &now
+	}
+}
+
+// Exposure records an ingress or load balancer service created to expose a UI.
+type Exposure struct {
+	Target    string `json:"target"`
+	Type      string `json:"type"`
+	Namespace string `json:"namespace"`
+	Resource  string `json:"resource"`
+	Host      string `json:"host,omitempty"`
+	TLSSecret string `json:"tlsSecret,omitempty"`
 }
 
 // Validate method to ensure only one operator is default, or none if Operators is nil
@@ -0,0 +1,32 @@
+package types
+
+import (
+	v1 "k8s.io/api/core/v1"
+)
+
+// Scheduling holds the constraints attached to the pods beamstack generates.
+type Scheduling struct {
+	NodeSelector      map[string]string `json:"nodeSelector,omitempty"`
+	Tolerations       []v1.Toleration   `json:"tolerations,omitempty"`
+	Affinity          *v1.Affinity      `json:"affinity,omitempty"`
+	PriorityClassName string            `json:"priorityClassName,omitempty"`
+}
+
+// Apply adds the scheduling constraints to a pod spec.
+func (s Scheduling) Apply(podSpec *v1.PodSpec) {
+	if len(s.NodeSelector) > 0 {
+		if podSpec.NodeSelector == nil {
+			podSpec.NodeSelector = map[string]string{}
+		}
+		for key, value := range s.NodeSelector {
+			podSpec.NodeSelector[key] = value
+		}
+	}
+	podSpec.Tolerations = append(podSpec.Tolerations, s.Tolerations...)
+	if s.Affinity != nil {
+		podSpec.Affinity = s.Affinity
+	}
+	if s.PriorityClassName != "" {
+		podSpec.PriorityClassName = s.PriorityClassName
+	}
+}
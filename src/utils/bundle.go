@@ -0,0 +1,290 @@
+package utils
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/BeamStackProj/beamstack-cli/src/types"
+	"helm.sh/helm/v3/pkg/chart/loader"
+)
+
+// Bundle is an extracted bundle of manifests and charts init installs from.
+type Bundle struct {
+	Dir      string
+	Manifest types.BundleManifest
+}
+
+// CreateBundle downloads the artifacts and writes them, along with a
+// manifest recording their checksums, into a gzipped tarball.
+func CreateBundle(output string, artifacts []types.Artifact) error {
+	dir, err := os.MkdirTemp("", "beamstack-bundle-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	for _, subdir := range []string{"manifests", "charts"} {
+		if err := os.MkdirAll(filepath.Join(dir, subdir), 0755); err != nil {
+			return err
+		}
+	}
+
+	manifest := types.BundleManifest{}
+	for _, artifact := range artifacts {
+		switch artifact.Type {
+		case types.ArtifactManifest:
+			fmt.Printf("downloading %s\n", artifact.Source)
+			u, err := url.Parse(artifact.Source)
+			if err != nil {
+				return err
+			}
+			artifact.File = path.Join("manifests", fmt.Sprintf("%s-%s", artifact.Component, path.Base(u.Path)))
+			if !strings.HasSuffix(artifact.File, ".yaml") {
+				artifact.File += ".yaml"
+			}
+			if err := downloadTo(artifact.Source, filepath.Join(dir, artifact.File)); err != nil {
+				return fmt.Errorf("could not download %s: %s", artifact.Source, err)
+			}
+
+		case types.ArtifactChart:
+			fmt.Printf("downloading chart %s from %s\n", artifact.Chart, artifact.Source)
+			archive, err := PullHelmChart(artifact.Chart, artifact.Source, artifact.Version, filepath.Join(dir, "charts"))
+			if err != nil {
+				return fmt.Errorf("could not download chart %s: %s", artifact.Chart, err)
+			}
+			chart, err := loader.Load(archive)
+			if err != nil {
+				return err
+			}
+			artifact.Version = chart.Metadata.Version
+			artifact.File = path.Join("charts", filepath.Base(archive))
+
+		default:
+			return fmt.Errorf("unknown artifact type %s", artifact.Type)
+		}
+
+		artifact.Sha256, err = fileChecksum(filepath.Join(dir, artifact.File))
+		if err != nil {
+			return err
+		}
+		manifest.Artifacts = append(manifest.Artifacts, artifact)
+	}
+
+	manifestData, err := json.MarshalIndent(manifest, "", "    ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dir, types.BundleManifestFile), manifestData, 0644); err != nil {
+		return err
+	}
+
+	return writeTarball(dir, output)
+}
+
+// OpenBundle extracts a bundle into a temporary directory and verifies the
+// checksums of its artifacts.
+func OpenBundle(bundlePath string) (*Bundle, error) {
+	dir, err := os.MkdirTemp("", "beamstack-bundle-")
+	if err != nil {
+		return nil, err
+	}
+	bundle := &Bundle{Dir: dir}
+
+	if err := extractTarball(bundlePath, dir); err != nil {
+		bundle.Close()
+		return nil, fmt.Errorf("could not extract bundle %s: %s", bundlePath, err)
+	}
+
+	if err := ParseJSON(filepath.Join(dir, types.BundleManifestFile), &bundle.Manifest); err != nil {
+		bundle.Close()
+		return nil, fmt.Errorf("could not read manifest of bundle %s: %s", bundlePath, err)
+	}
+
+	for _, artifact := range bundle.Manifest.Artifacts {
+		checksum, err := fileChecksum(filepath.Join(dir, filepath.FromSlash(artifact.File)))
+		if err != nil {
+			bundle.Close()
+			return nil, err
+		}
+		if checksum != artifact.Sha256 {
+			bundle.Close()
+			return nil, fmt.Errorf("checksum mismatch for %s in bundle %s", artifact.File, bundlePath)
+		}
+	}
+
+	return bundle, nil
+}
+
+// Path returns the local path of the bundled manifest or chart downloaded from source.
+func (b *Bundle) Path(source string, chart string) (string, bool) {
+	for _, artifact := range b.Manifest.Artifacts {
+		if artifact.Source == source && artifact.Chart == chart {
+			return filepath.Join(b.Dir, filepath.FromSlash(artifact.File)), true
+		}
+	}
+	return "", false
+}
+
+// Close removes the extracted bundle.
+func (b *Bundle) Close() error {
+	return os.RemoveAll(b.Dir)
+}
+
+func downloadTo(source string, dest string) error {
+	resp, err := http.Get(source)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	file, err := os.Create(dest)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(file, resp.Body)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+func fileChecksum(filePath string) (string, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// writeTarball writes the directory to a gzipped tarball. A tarball that
+// could not be written completely is removed.
+func writeTarball(dir string, output string) error {
+	file, err := os.Create(output)
+	if err != nil {
+		return err
+	}
+
+	gw := gzip.NewWriter(file)
+	tw := tar.NewWriter(gw)
+
+	err = writeTarEntries(tw, dir)
+	// the writers are closed in order, so the buffered data they flush reaches the file
+	if closeErr := tw.Close(); err == nil {
+		err = closeErr
+	}
+	if closeErr := gw.Close(); err == nil {
+		err = closeErr
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(output)
+		return fmt.Errorf("could not write bundle %s: %v", output, err)
+	}
+	return nil
+}
+
+func writeTarEntries(tw *tar.Writer, dir string) error {
+	return filepath.Walk(dir, func(filePath string, fi os.FileInfo, err error) error {
+		if err != nil || filePath == dir {
+			return err
+		}
+
+		header, err := tar.FileInfoHeader(fi, "")
+		if err != nil {
+			return err
+		}
+		relativePath, err := filepath.Rel(dir, filePath)
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(relativePath)
+
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if !fi.Mode().IsRegular() {
+			return nil
+		}
+
+		src, err := os.Open(filePath)
+		if err != nil {
+			return err
+		}
+		defer src.Close()
+		_, err = io.Copy(tw, src)
+		return err
+	})
+}
+
+func extractTarball(tarball string, dir string) error {
+	file, err := os.Open(tarball)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	gr, err := gzip.NewReader(file)
+	if err != nil {
+		return err
+	}
+	defer gr.Close()
+
+	tr := tar.NewReader(gr)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		target := filepath.Join(dir, filepath.FromSlash(header.Name))
+		if !strings.HasPrefix(target, filepath.Clean(dir)+string(os.PathSeparator)) {
+			return fmt.Errorf("invalid path %s", header.Name)
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return err
+			}
+			f, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+			if err != nil {
+				return err
+			}
+			if _, err := io.Copy(f, tr); err != nil {
+				f.Close()
+				return err
+			}
+			f.Close()
+		}
+	}
+}
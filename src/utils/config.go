@@ -13,8 +13,21 @@ import (
 var (
 	configPath         string
 	ValidateConfigOnce sync.Once
+	// Namespace is the namespace of user workloads given with the global --namespace flag
+	Namespace string
+	// Environment is the beamstack environment selected with the global --env flag
+	Environment string = types.DefaultEnvironment
 )
 
+// WorkloadNamespace returns the namespace of user workloads such as clusters
+// and pipelines: the --namespace flag, or the given default.
+func WorkloadNamespace(defaultNamespace string) string {
+	if Namespace != "" {
+		return Namespace
+	}
+	return defaultNamespace
+}
+
 func InitConfig() {
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
@@ -42,14 +55,16 @@ func ValidateCluster() (profile types.Profiles, err error) {
 				err = fmt.Errorf("error getting current context: %v", _err)
 				return
 			}
-			contextsStringMap := viper.GetStringMapString("contexts")
-
-			if _, ok := contextsStringMap[currentContext]; !ok {
+			profileName, ok := EnvironmentProfiles(currentContext)[Environment]
+			if !ok && Environment != types.DefaultEnvironment {
+				err = fmt.Errorf("environment %s not initialized on this cluster. please run 'beamstack init --env %s'", Environment, Environment)
+				return
+			} else if !ok {
 				err = fmt.Errorf("cluster not initialized. please run 'beamstack init' to initialize cluster")
 				return
 			}
 
-			profile, _err = GetProfile(contextsStringMap[currentContext])
+			profile, _err = GetProfile(profileName)
 			if _err != nil {
 				err = fmt.Errorf("error getting current profile: %v", _err)
 				return
@@ -58,3 +73,83 @@ func ValidateCluster() (profile types.Profiles, err error) {
 	)
 	return
 }
+
+// EnvironmentProfiles returns the profiles of the environments of a kube
+// context, keyed by environment. The default environment is mapped in
+// contexts, as before environments, and the others in environments.
+func EnvironmentProfiles(context string) map[string]string {
+	profiles := map[string]string{}
+	if profile, ok := viper.GetStringMapString("contexts")[context]; ok {
+		profiles[types.DefaultEnvironment] = profile
+	}
+	if environments, ok := viper.GetStringMap("environments")[context].(map[string]interface{}); ok {
+		for environment, profile := range environments {
+			if profile, ok := profile.(string); ok {
+				profiles[environment] = profile
+			}
+		}
+	}
+	return profiles
+}
+
+// SetEnvironmentProfile maps an environment of a kube context to a profile,
+// or unmaps it when the profile is empty, and writes the config file.
+func SetEnvironmentProfile(context string, environment string, profile string) error {
+	if environment == types.DefaultEnvironment {
+		contexts := viper.GetStringMapString("contexts")
+		if profile == "" {
+			delete(contexts, context)
+		} else {
+			contexts[context] = profile
+		}
+		viper.Set("contexts", contexts)
+	} else {
+		environments := viper.GetStringMap("environments")
+		profiles, _ := environments[context].(map[string]interface{})
+		if profiles == nil {
+			profiles = map[string]interface{}{}
+		}
+		if profile == "" {
+			delete(profiles, environment)
+		} else {
+			profiles[environment] = profile
+		}
+		if len(profiles) == 0 {
+			delete(environments, context)
+		} else {
+			environments[context] = profiles
+		}
+		viper.Set("environments", environments)
+	}
+	return viper.WriteConfig()
+}
+
+// ProfileInUse reports whether an environment of any kube context is mapped to the profile.
+func ProfileInUse(profile string) bool {
+	contexts := map[string]bool{}
+	for context := range viper.GetStringMapString("contexts") {
+		contexts[context] = true
+	}
+	for context := range viper.GetStringMap("environments") {
+		contexts[context] = true
+	}
+
+	for context := range contexts {
+		for _, name := range EnvironmentProfiles(context) {
+			if name == profile {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// EnvironmentLabels adds the label of the selected environment to the labels
+// of a resource created by a command.
+func EnvironmentLabels(labels map[string]string) map[string]string {
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	labels[types.ProfileEnvironmentLabel] = Environment
+	return labels
+}
@@ -1,15 +1,22 @@
 package utils
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
+	"reflect"
+	"time"
 
 	"github.com/BeamStackProj/beamstack-cli/src/types"
 	"helm.sh/helm/v3/pkg/action"
 	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/chartutil"
 	"helm.sh/helm/v3/pkg/cli"
 	"helm.sh/helm/v3/pkg/getter"
+	"helm.sh/helm/v3/pkg/release"
 	"helm.sh/helm/v3/pkg/repo"
+	"helm.sh/helm/v3/pkg/storage/driver"
 )
 
 func InstallHelmPackage(name string, index string, url string, version string, namespace string, values *map[string]interface{}) (helmPackage types.Package) {
@@ -60,6 +67,7 @@ func InstallHelmPackage(name string, index string, url string, version string, n
 	install := action.NewInstall(actionConfig)
 	install.ReleaseName = name
 	install.Namespace = namespace
+	install.Version = version
 	if index == "" {
 		index = name
 	}
@@ -68,10 +76,45 @@ func InstallHelmPackage(name string, index string, url string, version string, n
 		panic(err.Error())
 	}
 
+	installHelmChart(actionConfig, install, &helmPackage, chartPath, values)
+	return
+}
+
+// InstallLocalHelmPackage installs a chart archive or directory from the
+// local filesystem, without contacting any chart repository.
+func InstallLocalHelmPackage(name string, chartPath string, version string, namespace string, values *map[string]interface{}) (helmPackage types.Package) {
+	helmPackage = types.Package{
+		Name:    name,
+		Url:     chartPath,
+		Version: version,
+		Type:    "helm",
+	}
+
+	if namespace == "" {
+		namespace = "default"
+	}
+
+	actionConfig := new(action.Configuration)
+	if err := actionConfig.Init(cli.New().RESTClientGetter(), namespace, os.Getenv("HELM_DRIVER"), func(format string, v ...interface{}) {
+		fmt.Printf(format, v...)
+	}); err != nil {
+		panic(err.Error())
+	}
+
+	install := action.NewInstall(actionConfig)
+	install.ReleaseName = name
+	install.Namespace = namespace
+
+	installHelmChart(actionConfig, install, &helmPackage, chartPath, values)
+	return
+}
+
+func installHelmChart(actionConfig *action.Configuration, install *action.Install, helmPackage *types.Package, chartPath string, values *map[string]interface{}) {
 	chart, err := loader.Load(chartPath)
 	if err != nil {
 		panic(err.Error())
 	}
+	helmPackage.Namespace = install.Namespace
 
 	for _, crd := range chart.CRDObjects() {
 		helmPackage.Dependencies = append(helmPackage.Dependencies, &types.Package{
@@ -81,13 +124,284 @@ func InstallHelmPackage(name string, index string, url string, version string, n
 			Version: crd.File.Name,
 		})
 	}
+
+	// a deployed release is upgraded in place, so the resources its operator manages survive
+	if existing, err := action.NewGet(actionConfig).Run(install.ReleaseName); err == nil && existing.Info != nil && existing.Info.Status == release.StatusDeployed {
+		upgrade := action.NewUpgrade(actionConfig)
+		upgrade.Namespace = install.Namespace
+		upgrade.Version = install.Version
+		upgrade.ResetValues = true
+		if _, err := upgrade.Run(install.ReleaseName, chart, *values); err != nil {
+			panic(err.Error())
+		}
+		return
+	}
+
 	delete := action.NewUninstall(actionConfig)
-	_, _ = delete.Run(name)
+	_, _ = delete.Run(install.ReleaseName)
 	_, err = install.Run(chart, *values)
 	if err != nil {
 		panic(err.Error())
 	}
-	return
+}
+
+// PullHelmChart downloads the archive of a chart from a repository into the
+// directory and returns its path.
+func PullHelmChart(chartName string, url string, version string, dir string) (string, error) {
+	pull := action.NewPullWithOpts(action.WithConfig(new(action.Configuration)))
+	pull.Settings = cli.New()
+	pull.RepoURL = url
+	pull.Version = version
+	pull.DestDir = dir
+
+	if _, err := pull.Run(chartName); err != nil {
+		return "", err
+	}
+
+	archives, err := filepath.Glob(filepath.Join(dir, fmt.Sprintf("%s-*.tgz", chartName)))
+	if err != nil {
+		return "", err
+	}
+	if len(archives) == 0 {
+		return "", fmt.Errorf("chart %s was not downloaded from %s", chartName, url)
+	}
+	return archives[0], nil
+}
+
+// UpgradeHelmPackage upgrades a release to a chart archive or directory, keeping
+// the values it was installed with. Helm does not upgrade the custom resource
+// definitions of a chart, see HelmChartCRDs.
+func UpgradeHelmPackage(name string, chartPath string, version string, namespace string) (helmPackage types.Package, err error) {
+	if namespace == "" {
+		if namespace, err = helmReleaseNamespace(name); err != nil {
+			return helmPackage, err
+		} else if namespace == "" {
+			return helmPackage, fmt.Errorf("helm release %s not found", name)
+		}
+	}
+
+	helmPackage = types.Package{
+		Name:      name,
+		Url:       chartPath,
+		Version:   version,
+		Namespace: namespace,
+		Type:      "helm",
+	}
+
+	actionConfig := new(action.Configuration)
+	if err := actionConfig.Init(cli.New().RESTClientGetter(), namespace, os.Getenv("HELM_DRIVER"), func(format string, v ...interface{}) {
+		fmt.Printf(format, v...)
+	}); err != nil {
+		return helmPackage, err
+	}
+
+	chart, err := loader.Load(chartPath)
+	if err != nil {
+		return helmPackage, err
+	}
+	if helmPackage.Version == "" && chart.Metadata != nil {
+		helmPackage.Version = chart.Metadata.Version
+	}
+	for _, crd := range chart.CRDObjects() {
+		helmPackage.Dependencies = append(helmPackage.Dependencies, &types.Package{
+			Name:    crd.Name,
+			Url:     crd.Filename,
+			Type:    "k8s.crd",
+			Version: crd.File.Name,
+		})
+	}
+
+	upgrade := action.NewUpgrade(actionConfig)
+	upgrade.Namespace = namespace
+	upgrade.ReuseValues = true
+	upgrade.Wait = true
+	upgrade.Timeout = 10 * time.Minute
+	if _, err := upgrade.Run(name, chart, map[string]interface{}{}); err != nil {
+		return helmPackage, fmt.Errorf("could not upgrade helm release %s: %v", name, err)
+	}
+	return helmPackage, nil
+}
+
+// HelmChartCRDs returns the custom resource definitions of a chart archive or directory.
+func HelmChartCRDs(chartPath string) ([][]byte, error) {
+	chart, err := loader.Load(chartPath)
+	if err != nil {
+		return nil, err
+	}
+
+	crds := [][]byte{}
+	for _, crd := range chart.CRDObjects() {
+		crds = append(crds, crd.File.Data)
+	}
+	return crds, nil
+}
+
+// HelmReleaseVersion returns the chart version of an installed release.
+func HelmReleaseVersion(name string, namespace string) (string, error) {
+	installed, err := helmRelease(name, namespace)
+	if err != nil || installed == nil {
+		return "", err
+	}
+	if installed.Chart == nil || installed.Chart.Metadata == nil {
+		return "", nil
+	}
+	return installed.Chart.Metadata.Version, nil
+}
+
+// HelmReleaseMatches reports whether a release is deployed from a chart
+// version with exactly the given values. An empty version matches any chart
+// version.
+func HelmReleaseMatches(name string, namespace string, version string, values map[string]interface{}) (bool, error) {
+	installed, err := helmRelease(name, namespace)
+	if err != nil || installed == nil {
+		return false, err
+	}
+	if installed.Info == nil || installed.Info.Status != release.StatusDeployed {
+		return false, nil
+	}
+	if version != "" && (installed.Chart == nil || installed.Chart.Metadata == nil || installed.Chart.Metadata.Version != version) {
+		return false, nil
+	}
+
+	// releases store their values as json, so the values are compared the same way
+	want, err := json.Marshal(values)
+	if err != nil {
+		return false, err
+	}
+	got, err := json.Marshal(installed.Config)
+	if err != nil {
+		return false, err
+	}
+	var wantValues, gotValues map[string]interface{}
+	if err := json.Unmarshal(want, &wantValues); err != nil {
+		return false, err
+	}
+	if err := json.Unmarshal(got, &gotValues); err != nil {
+		return false, err
+	}
+	if len(wantValues) == 0 && len(gotValues) == 0 {
+		return true, nil
+	}
+	return reflect.DeepEqual(wantValues, gotValues), nil
+}
+
+// helmRelease returns an installed release, or nil if there is none.
+func helmRelease(name string, namespace string) (*release.Release, error) {
+	if namespace == "" {
+		var err error
+		if namespace, err = helmReleaseNamespace(name); err != nil || namespace == "" {
+			return nil, err
+		}
+	}
+
+	actionConfig := new(action.Configuration)
+	if err := actionConfig.Init(cli.New().RESTClientGetter(), namespace, os.Getenv("HELM_DRIVER"), func(format string, v ...interface{}) {
+		fmt.Printf(format, v...)
+	}); err != nil {
+		return nil, err
+	}
+
+	installed, err := action.NewGet(actionConfig).Run(name)
+	if err == driver.ErrReleaseNotFound {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return installed, nil
+}
+
+// UninstallHelmPackage uninstalls the release of a helm package and returns
+// the custom resource definitions of its chart, which helm leaves in place.
+// A release that no longer exists is not an error.
+func UninstallHelmPackage(name string, namespace string) (crds [][]byte, err error) {
+	if namespace == "" {
+		if namespace, err = helmReleaseNamespace(name); err != nil || namespace == "" {
+			return nil, err
+		}
+	}
+
+	actionConfig := new(action.Configuration)
+	if err := actionConfig.Init(cli.New().RESTClientGetter(), namespace, os.Getenv("HELM_DRIVER"), func(format string, v ...interface{}) {
+		fmt.Printf(format, v...)
+	}); err != nil {
+		return nil, err
+	}
+
+	release, err := action.NewGet(actionConfig).Run(name)
+	if err == driver.ErrReleaseNotFound {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if release.Chart != nil {
+		for _, crd := range release.Chart.CRDObjects() {
+			crds = append(crds, crd.File.Data)
+		}
+	}
+
+	uninstall := action.NewUninstall(actionConfig)
+	uninstall.Wait = true
+	uninstall.Timeout = 5 * time.Minute
+	if _, err := uninstall.Run(name); err != nil {
+		return nil, fmt.Errorf("could not uninstall helm release %s: %v", name, err)
+	}
+	return crds, nil
+}
+
+// helmReleaseNamespace looks up the namespace of a release in every namespace,
+// for packages recorded before their namespace was.
+func helmReleaseNamespace(name string) (string, error) {
+	actionConfig := new(action.Configuration)
+	if err := actionConfig.Init(cli.New().RESTClientGetter(), "", os.Getenv("HELM_DRIVER"), func(format string, v ...interface{}) {
+		fmt.Printf(format, v...)
+	}); err != nil {
+		return "", err
+	}
+
+	list := action.NewList(actionConfig)
+	list.AllNamespaces = true
+	list.All = true
+	releases, err := list.Run()
+	if err != nil {
+		return "", err
+	}
+	for _, release := range releases {
+		if release.Name == name {
+			return release.Namespace, nil
+		}
+	}
+	return "", nil
+}
+
+// MergeValues deep merges helm values over the defaults, returning a new map.
+// Maps are merged key by key, any other value replaces the default.
+func MergeValues(defaults map[string]interface{}, values map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(defaults))
+	for key, value := range defaults {
+		if table, ok := value.(map[string]interface{}); ok {
+			value = MergeValues(table, nil)
+		}
+		merged[key] = value
+	}
+
+	for key, value := range values {
+		table, ok := value.(map[string]interface{})
+		if defaultTable, isTable := merged[key].(map[string]interface{}); ok && isTable {
+			merged[key] = MergeValues(defaultTable, table)
+			continue
+		}
+		merged[key] = value
+	}
+	return merged
+}
+
+// LoadValuesFile reads a helm values file.
+func LoadValuesFile(path string) (map[string]interface{}, error) {
+	values, err := chartutil.ReadValuesFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read values file %s: %v", path, err)
+	}
+	return values.AsMap(), nil
 }
 
 func updateHelmRepositories() error {
@@ -0,0 +1,96 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestMergeValues(t *testing.T) {
+	defaults := map[string]interface{}{
+		"image": map[string]interface{}{
+			"repository": "flink",
+			"tag":        "1.16",
+		},
+		"watchNamespaces": []interface{}{"flink"},
+		"replicas":        1,
+		"operator": map[string]interface{}{
+			"resources": map[string]interface{}{
+				"limits": map[string]interface{}{"cpu": "1", "memory": "1Gi"},
+			},
+		},
+	}
+
+	merged := MergeValues(defaults, map[string]interface{}{
+		"image":           map[string]interface{}{"tag": "1.17"},
+		"watchNamespaces": []interface{}{"analytics"},
+		"operator": map[string]interface{}{
+			"resources": map[string]interface{}{
+				"limits": map[string]interface{}{"memory": "2Gi"},
+			},
+		},
+		"rbac": map[string]interface{}{"create": false},
+	})
+
+	want := map[string]interface{}{
+		"image": map[string]interface{}{
+			"repository": "flink",
+			"tag":        "1.17",
+		},
+		"watchNamespaces": []interface{}{"analytics"},
+		"replicas":        1,
+		"operator": map[string]interface{}{
+			"resources": map[string]interface{}{
+				"limits": map[string]interface{}{"cpu": "1", "memory": "2Gi"},
+			},
+		},
+		"rbac": map[string]interface{}{"create": false},
+	}
+	if !reflect.DeepEqual(merged, want) {
+		t.Errorf("got %v, want %v", merged, want)
+	}
+
+	if defaults["image"].(map[string]interface{})["tag"] != "1.16" {
+		t.Error("MergeValues modified the defaults")
+	}
+}
+
+func TestMergeValuesReplacesTablesWithScalars(t *testing.T) {
+	merged := MergeValues(
+		map[string]interface{}{"persistence": map[string]interface{}{"enabled": true}, "mode": "standalone"},
+		map[string]interface{}{"persistence": nil, "mode": map[string]interface{}{"distributed": true}},
+	)
+
+	want := map[string]interface{}{"persistence": nil, "mode": map[string]interface{}{"distributed": true}}
+	if !reflect.DeepEqual(merged, want) {
+		t.Errorf("got %v, want %v", merged, want)
+	}
+}
+
+func TestLoadProfileConfigMergesValuesFiles(t *testing.T) {
+	dir := t.TempDir()
+	values := "image:\n  repository: flink\n  tag: \"1.16\"\nwatchNamespaces:\n  - flink\n  - batch\n"
+	if err := os.WriteFile(filepath.Join(dir, "flink.yaml"), []byte(values), 0644); err != nil {
+		t.Fatal(err)
+	}
+	config := "components:\n  flink-operator:\n    valuesFile: flink.yaml\n    values:\n      image:\n        tag: \"1.17\"\n      watchNamespaces:\n        - analytics\n"
+	path := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(path, []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := LoadProfileConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := loaded.Component("flink-operator").Values
+	want := map[string]interface{}{
+		"image":           map[string]interface{}{"repository": "flink", "tag": "1.17"},
+		"watchNamespaces": []interface{}{"analytics"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -9,30 +10,59 @@ import (
 
 	"github.com/BeamStackProj/beamstack-cli/src/types"
 	"gopkg.in/yaml.v2"
+	k8syaml "sigs.k8s.io/yaml"
 )
 
-func LoadProfileFromConfig(configFile string) (profile types.Profiles, err error) {
+// LoadProfileConfig reads a YAML or JSON profile configuration file. Fields
+// the configuration does not define are rejected.
+func LoadProfileConfig(configFile string) (config types.ProfileConfig, err error) {
 	// Check if the config file exists
 	if _, err := os.Stat(configFile); os.IsNotExist(err) {
-		return profile, fmt.Errorf("config file not found at %s", configFile)
+		return config, fmt.Errorf("config file not found at %s", configFile)
 	} else if err != nil {
-		return profile, fmt.Errorf("error checking config file: %v", err)
+		return config, fmt.Errorf("error checking config file: %v", err)
 	}
 
-	// Get file extension
-	ext := filepath.Ext(configFile)
+	data, err := os.ReadFile(configFile)
+	if err != nil {
+		return config, fmt.Errorf("error reading config file: %v", err)
+	}
 
 	// Check if the file type is supported
-	switch ext {
+	switch ext := filepath.Ext(configFile); ext {
 	case ".yaml", ".yml":
-		err = ParseYAML(configFile, &profile)
+		err = k8syaml.UnmarshalStrict(data, &config)
 	case ".json":
-		err = ParseJSON(configFile, &profile)
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(&config)
 	default:
-		return profile, fmt.Errorf("unsupported file type: %s", ext)
+		return config, fmt.Errorf("unsupported file type: %s", ext)
+	}
+	if err != nil {
+		return config, fmt.Errorf("invalid config file %s: %v", configFile, err)
 	}
 
-	return profile, err
+	if err := config.Validate(); err != nil {
+		return config, fmt.Errorf("invalid config file %s: %v", configFile, err)
+	}
+
+	// values files are read once, so the profile records the values init installed with
+	for name, component := range config.Components {
+		if component.ValuesFile == "" {
+			continue
+		}
+		if !filepath.IsAbs(component.ValuesFile) {
+			component.ValuesFile = filepath.Join(filepath.Dir(configFile), component.ValuesFile)
+		}
+		values, err := LoadValuesFile(component.ValuesFile)
+		if err != nil {
+			return config, fmt.Errorf("invalid config file %s: %s: %v", configFile, name, err)
+		}
+		component.Values = MergeValues(values, component.Values)
+		config.Components[name] = component
+	}
+	return config, nil
 }
 
 func ParseYAML(filePath string, out interface{}) error {
@@ -54,6 +84,21 @@ func ParseYAML(filePath string, out interface{}) error {
 	return nil
 }
 
+// ParseYAMLAsJSON parses YAML into types that only carry json tags, such as profiles
+// and kubernetes api types.
+func ParseYAMLAsJSON(filePath string, out interface{}) error {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return fmt.Errorf("error reading file: %v", err)
+	}
+
+	if err := k8syaml.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("error parsing YAML: %v", err)
+	}
+
+	return nil
+}
+
 func ParseJSON(filePath string, out interface{}) error {
 	// Open the JSON file
 	file, err := os.Open(filePath)
@@ -109,3 +154,17 @@ func GetProfile(profileName string) (profile types.Profiles, err error) {
 	}
 	return
 }
+
+// DeleteProfile removes the profile file.
+func DeleteProfile(profileName string) error {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return fmt.Errorf("could not locate home directory %s", err)
+	}
+
+	profilepath := filepath.Join(homeDir, ".beamstack", "profiles", fmt.Sprintf("%s.json", profileName))
+	if err := os.Remove(profilepath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("error removing profile file, %s", err)
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadProfileConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		err     string
+	}{
+		{
+			name: "yaml",
+			file: "config.yaml",
+			content: `
+name: analytics
+components:
+  flink-operator:
+    namespace: streaming
+scheduling:
+  nodeSelector:
+    pool: beam
+`,
+		},
+		{
+			name:    "json",
+			file:    "config.json",
+			content: `{"components": {"flink-operator": {"namespace": "streaming"}}, "scheduling": {"nodeSelector": {"pool": "beam"}}}`,
+		},
+		{
+			name: "unknown yaml field",
+			file: "config.yaml",
+			content: `
+components:
+  flink-operator:
+    namespaces: streaming
+`,
+			err: "namespaces",
+		},
+		{
+			name:    "unknown json field",
+			file:    "config.json",
+			content: `{"components": {"flink-operator": {"namespace": "streaming"}}, "operators": {}}`,
+			err:     "operators",
+		},
+		{
+			name:    "unknown scheduling field",
+			file:    "config.yaml",
+			content: "components: {}\nscheduling:\n  nodeSelectors: {}\n",
+			err:     "nodeSelectors",
+		},
+		{
+			name:    "invalid component",
+			file:    "config.yaml",
+			content: "components:\n  spark: {}\n",
+			err:     "unknown component spark",
+		},
+		{
+			name:    "unsupported file type",
+			file:    "config.toml",
+			content: "",
+			err:     "unsupported file type",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), test.file)
+			if err := os.WriteFile(path, []byte(test.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			config, err := LoadProfileConfig(path)
+			if test.err != "" {
+				if err == nil || !strings.Contains(err.Error(), test.err) {
+					t.Errorf("got error %v, want one containing %q", err, test.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if config.Namespace("flink-operator") != "streaming" {
+				t.Errorf("got namespace %s, want streaming", config.Namespace("flink-operator"))
+			}
+			if config.Scheduling == nil || config.Scheduling.NodeSelector["pool"] != "beam" {
+				t.Errorf("unexpected scheduling %+v", config.Scheduling)
+			}
+		})
+	}
+}
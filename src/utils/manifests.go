@@ -0,0 +1,48 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// manifestPath returns where the copy of a manifest applied from a source is kept.
+func manifestPath(source string) (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("could not locate home directory %s", err)
+	}
+
+	sum := sha256.Sum256([]byte(source))
+	return filepath.Join(homeDir, ".beamstack", "manifests", hex.EncodeToString(sum[:])+".yaml"), nil
+}
+
+// SaveManifest keeps a copy of a manifest applied from a source, so its
+// objects can be deleted later without downloading it again.
+func SaveManifest(source string, data []byte) error {
+	path, err := manifestPath(source)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("error creating manifests directory, %s", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("error writing manifest of %s, %s", source, err)
+	}
+	return nil
+}
+
+// SavedManifest returns the kept copy of a manifest applied from a source, if any.
+func SavedManifest(source string) (string, bool) {
+	path, err := manifestPath(source)
+	if err != nil {
+		return "", false
+	}
+	if _, err := os.Stat(path); err != nil {
+		return "", false
+	}
+	return path, true
+}
@@ -11,6 +11,7 @@ import (
 	"strings"
 
 	"github.com/BeamStackProj/beamstack-cli/src/types"
+	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/remotecommand"
@@ -202,3 +203,33 @@ func MigrateFilesFromContainer(clientset *kubernetes.Clientset, params types.Mig
 
 	return nil
 }
+
+// ExecInContainer runs a command in a container of the pod, streaming its
+// output to the terminal.
+func ExecInContainer(clientset *kubernetes.Clientset, pod v1.Pod, container string, command []string) error {
+	config := GetKubeConfig()
+
+	req := clientset.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Name(pod.Name).
+		Namespace(pod.Namespace).
+		SubResource("exec").
+		Param("container", container).
+		Param("stdin", "false").
+		Param("stdout", "true").
+		Param("stderr", "true").
+		Param("tty", "false")
+	for _, arg := range command {
+		req = req.Param("command", arg)
+	}
+
+	exec, err := remotecommand.NewSPDYExecutor(config, "POST", req.URL())
+	if err != nil {
+		return err
+	}
+
+	return exec.StreamWithContext(context.TODO(), remotecommand.StreamOptions{
+		Stdout: os.Stdout,
+		Stderr: os.Stderr,
+	})
+}
@@ -0,0 +1,113 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/BeamStackProj/beamstack-cli/src/types"
+	"github.com/spf13/pflag"
+	v1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// SchedulingFlags holds the scheduling flags shared by commands that generate pods.
+type SchedulingFlags struct {
+	NodeSelector      map[string]string
+	Tolerations       []string
+	AffinityFile      string
+	PriorityClassName string
+}
+
+func AddSchedulingFlags(flags *pflag.FlagSet, f *SchedulingFlags) {
+	flags.StringToStringVar(&f.NodeSelector, "node-selector", f.NodeSelector, "node labels the generated pods must be scheduled on, e.g. pool=data")
+	flags.StringSliceVar(&f.Tolerations, "toleration", f.Tolerations, "toleration added to the generated pods in the form key[=value]:Effect. Can be repeated")
+	flags.StringVar(&f.AffinityFile, "affinity", f.AffinityFile, "path to a YAML or JSON file with the affinity of the generated pods")
+	flags.StringVar(&f.PriorityClassName, "priority-class", f.PriorityClassName, "priority class name of the generated pods")
+}
+
+// Resolve merges the flags over the profile scheduling defaults. Node selectors are merged
+// by key, tolerations are appended and affinity and priority class are replaced when set.
+func (f SchedulingFlags) Resolve(defaults *types.Scheduling) (scheduling types.Scheduling, err error) {
+	if defaults != nil {
+		scheduling.Tolerations = append(scheduling.Tolerations, defaults.Tolerations...)
+		scheduling.Affinity = defaults.Affinity
+		scheduling.PriorityClassName = defaults.PriorityClassName
+		if len(defaults.NodeSelector) > 0 {
+			scheduling.NodeSelector = map[string]string{}
+			for key, value := range defaults.NodeSelector {
+				scheduling.NodeSelector[key] = value
+			}
+		}
+	}
+
+	if len(f.NodeSelector) > 0 && scheduling.NodeSelector == nil {
+		scheduling.NodeSelector = map[string]string{}
+	}
+	for key, value := range f.NodeSelector {
+		scheduling.NodeSelector[key] = value
+	}
+
+	tolerations, err := ParseTolerations(f.Tolerations)
+	if err != nil {
+		return scheduling, err
+	}
+	scheduling.Tolerations = append(scheduling.Tolerations, tolerations...)
+
+	if f.AffinityFile != "" {
+		affinity, err := LoadAffinity(f.AffinityFile)
+		if err != nil {
+			return scheduling, err
+		}
+		scheduling.Affinity = affinity
+	}
+
+	if f.PriorityClassName != "" {
+		scheduling.PriorityClassName = f.PriorityClassName
+	}
+	return scheduling, nil
+}
+
+// ParseTolerations parses tolerations written like kubectl taints: key[=value]:Effect.
+// An empty effect tolerates every effect of the taint.
+func ParseTolerations(specs []string) ([]v1.Toleration, error) {
+	tolerations := []v1.Toleration{}
+	for _, spec := range specs {
+		keyValue, effect, _ := strings.Cut(spec, ":")
+		key, value, hasValue := strings.Cut(keyValue, "=")
+		if key == "" {
+			return nil, fmt.Errorf("invalid toleration %q: missing key", spec)
+		}
+
+		toleration := v1.Toleration{
+			Key:      key,
+			Operator: v1.TolerationOpExists,
+			Effect:   v1.TaintEffect(effect),
+		}
+		if hasValue {
+			toleration.Operator = v1.TolerationOpEqual
+			toleration.Value = value
+		}
+
+		switch toleration.Effect {
+		case "", v1.TaintEffectNoSchedule, v1.TaintEffectPreferNoSchedule, v1.TaintEffectNoExecute:
+		default:
+			return nil, fmt.Errorf("invalid toleration %q: unknown effect %s", spec, effect)
+		}
+		tolerations = append(tolerations, toleration)
+	}
+	return tolerations, nil
+}
+
+func LoadAffinity(path string) (*v1.Affinity, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading affinity file: %v", err)
+	}
+
+	affinity := &v1.Affinity{}
+	if err := yaml.UnmarshalStrict(data, affinity); err != nil {
+		return nil, fmt.Errorf("error parsing affinity file: %v", err)
+	}
+	return affinity, nil
+}